@@ -0,0 +1,64 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forwarder
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RewriteConfig is used to rewrite the outbound request before forwarding.
+type RewriteConfig struct {
+	// StripPrefix is the path prefix to be stripped from the request path,
+	// such as "/api" to rewrite "/api/users" to "/users".
+	StripPrefix string
+
+	// SetHost is used to reset the request header "Host",
+	// that's, r.Host, not the destination host r.URL.Host.
+	SetHost string
+
+	// SetHeaders is the request headers to be set.
+	SetHeaders map[string]string
+}
+
+// Rewrite returns a request function, which may be set to Forwarder.Request,
+// to rewrite the outbound request by config.
+//
+// Because Forwarder.Forward has cloned the request before calling it,
+// the original request of the caller is not changed.
+func Rewrite(config RewriteConfig) func(*http.Request) *http.Request {
+	prefix := strings.TrimRight(config.StripPrefix, "/")
+	return func(r *http.Request) *http.Request {
+		if prefix != "" && strings.HasPrefix(r.URL.Path, prefix) {
+			if path := r.URL.Path[len(prefix):]; path == "" || path[0] == '/' {
+				if path == "" {
+					path = "/"
+				}
+				r.URL.Path = path
+				r.URL.RawPath = ""
+			}
+		}
+
+		if config.SetHost != "" {
+			r.Host = config.SetHost
+		}
+
+		for key, value := range config.SetHeaders {
+			r.Header.Set(key, value)
+		}
+
+		return r
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forwarder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	var path, host, value string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, host, value = r.URL.Path, r.Host, r.Header.Get("X-Test")
+		w.WriteHeader(204)
+	}))
+	defer server.Close()
+
+	f := NewForwarder(server.Listener.Addr().String())
+	f.Request = Rewrite(RewriteConfig{
+		StripPrefix: "/api/",
+		SetHost:     "www.example.com",
+		SetHeaders:  map[string]string{"X-Test": "abc"},
+	})
+
+	tests := []struct {
+		path   string
+		expect string
+	}{
+		{path: "/api/users", expect: "/users"},
+		{path: "/api", expect: "/"},
+		{path: "/apis/users", expect: "/apis/users"},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		if err := f.Forward(rec, req, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.Code != 204 {
+			t.Errorf("%s: expect status code %d, but got %d", test.path, 204, rec.Code)
+		}
+		if path != test.expect {
+			t.Errorf("%s: expect path '%s', but got '%s'", test.path, test.expect, path)
+		}
+		if host != "www.example.com" {
+			t.Errorf("%s: expect host '%s', but got '%s'", test.path, "www.example.com", host)
+		}
+		if value != "abc" {
+			t.Errorf("%s: expect header value '%s', but got '%s'", test.path, "abc", value)
+		}
+
+		if req.URL.Path != test.path || req.Header.Get("X-Test") != "" || req.Host == "www.example.com" {
+			t.Errorf("%s: the original request is changed", test.path)
+		}
+	}
+}
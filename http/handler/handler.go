@@ -44,6 +44,10 @@ var (
 		w.WriteHeader(404)
 	})
 
+	Handler405 http.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(405)
+	})
+
 	Handler500 http.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(500)
 	})
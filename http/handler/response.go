@@ -16,6 +16,7 @@ package handler
 
 import (
 	"encoding/xml"
+	"fmt"
 	"io"
 	"net/http"
 	"sync"
@@ -67,6 +68,50 @@ func XML(w http.ResponseWriter, code int, v any) (err error) {
 	return
 }
 
+var (
+	// MsgPackEncoder is used by MsgPack to encode v into w.
+	//
+	// Default: nil, which lets MsgPack return an error without responding
+	MsgPackEncoder func(w io.Writer, v any) error
+
+	// YAMLEncoder is used by YAML to encode v into w.
+	//
+	// Default: nil, which lets YAML return an error without responding
+	YAMLEncoder func(w io.Writer, v any) error
+)
+
+// MsgPack sends the response by the msgpack format to the client,
+// which uses MsgPackEncoder to encode v.
+func MsgPack(w http.ResponseWriter, code int, v any) (err error) {
+	return encode(w, code, v, header.MIMEApplicationMsgpack, "MsgPack", MsgPackEncoder)
+}
+
+// YAML sends the response by the yaml format to the client,
+// which uses YAMLEncoder to encode v.
+func YAML(w http.ResponseWriter, code int, v any) (err error) {
+	return encode(w, code, v, header.MIMEApplicationYAML, "YAML", YAMLEncoder)
+}
+
+func encode(w http.ResponseWriter, code int, v any, ct, name string,
+	encoder func(io.Writer, any) error) (err error) {
+	if encoder == nil {
+		return fmt.Errorf("handler.%s: no encoder is configured, please set handler.%sEncoder", name, name)
+	} else if v == nil {
+		w.WriteHeader(code)
+		return
+	}
+
+	buf := getBuilder()
+	if err = encoder(buf, v); err == nil {
+		header.SetContentType(w.Header(), ct)
+		w.WriteHeader(code)
+		_, err = buf.WriteTo(w)
+	}
+	putBuilder(buf)
+
+	return
+}
+
 /// ----------------------------------------------------------------------- ///
 
 type builder struct{ buf []byte }
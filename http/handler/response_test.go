@@ -16,8 +16,13 @@ package handler
 
 import (
 	"encoding/xml"
+	"fmt"
+	"io"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/xgfone/go-apiserver/http/header"
 )
 
 func TestJSON(t *testing.T) {
@@ -59,3 +64,72 @@ func TestXML(t *testing.T) {
 		t.Errorf("expect response body '%s', but got '%s'", expectbody, body)
 	}
 }
+
+func TestMsgPack(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if err := MsgPack(rec, 200, map[string]string{"a": "b"}); err == nil {
+		t.Errorf("expect an error, but got nil")
+	} else if !strings.Contains(err.Error(), "MsgPackEncoder") {
+		t.Errorf("expect the error to mention MsgPackEncoder, but got '%s'", err.Error())
+	}
+	if err := MsgPack(rec, 200, nil); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+	if rec.Body.Len() != 0 || len(rec.Header()) != 0 {
+		t.Errorf("expect nothing responded, but got header %v and body '%s'", rec.Header(), rec.Body.String())
+	}
+
+	defer func() { MsgPackEncoder = nil }()
+	MsgPackEncoder = func(w io.Writer, v any) error {
+		for k, v := range v.(map[string]string) { // fixstr map with one pair
+			_, _ = w.Write([]byte{0x81, 0xa0 | byte(len(k))})
+			_, _ = io.WriteString(w, k)
+			_, _ = w.Write([]byte{0xa0 | byte(len(v))})
+			_, _ = io.WriteString(w, v)
+		}
+		return nil
+	}
+
+	if err := MsgPack(rec, 400, map[string]string{"a": "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Code != 400 {
+		t.Errorf("expect status code %d, but got %d", 400, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != header.MIMEApplicationMsgpack {
+		t.Errorf("expect content type '%s', but got '%s'", header.MIMEApplicationMsgpack, ct)
+	}
+	if body := rec.Body.String(); body != "\x81\xa1a\xa1b" {
+		t.Errorf("expect response body %q, but got %q", "\x81\xa1a\xa1b", body)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if err := YAML(rec, 200, map[string]string{"a": "b"}); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	defer func() { YAMLEncoder = nil }()
+	YAMLEncoder = func(w io.Writer, v any) error {
+		for k, v := range v.(map[string]string) {
+			fmt.Fprintf(w, "%s: %s\n", k, v)
+		}
+		return nil
+	}
+
+	if err := YAML(rec, 400, map[string]string{"a": "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Code != 400 {
+		t.Errorf("expect status code %d, but got %d", 400, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/yaml" {
+		t.Errorf("expect content type '%s', but got '%s'", "application/yaml", ct)
+	}
+	if body := rec.Body.String(); body != "a: b\n" {
+		t.Errorf("expect response body '%s', but got '%s'", "a: b\n", body)
+	}
+}
@@ -29,9 +29,11 @@ const (
 	MIMEApplicationJSON        = "application/json"
 	MIMEApplicationProtobuf    = "application/protobuf"
 	MIMEApplicationMsgpack     = "application/msgpack"
+	MIMEApplicationYAML        = "application/yaml" // RFC 9512
 	MIMEApplicationOctetStream = "application/octet-stream"
 	MIMEApplicationForm        = "application/x-www-form-urlencoded"
 	MIMEMultipartForm          = "multipart/form-data"
+	MIMEApplicationProblemJSON = "application/problem+json" // RFC 7807
 
 	MIMETextXMLCharsetUTF8         = MIMETextXML + "; charset=UTF-8"
 	MIMETextHTMLCharsetUTF8        = MIMETextHTML + "; charset=UTF-8"
@@ -127,4 +129,5 @@ const (
 	HeaderXXSSProtection          = "X-Xss-Protection"
 	HeaderXFrameOptions           = "X-Frame-Options"
 	HeaderXCSRFToken              = "X-Csrf-Token"
+	HeaderReferrerPolicy          = "Referrer-Policy"
 )
@@ -30,6 +30,7 @@ var (
 	mimeApplicationJSON        = []string{MIMEApplicationJSON}
 	mimeApplicationForm        = []string{MIMEApplicationForm}
 	mimeApplicationMsgpack     = []string{MIMEApplicationMsgpack}
+	mimeApplicationYAML        = []string{MIMEApplicationYAML}
 	mimeApplicationProtobuf    = []string{MIMEApplicationProtobuf}
 	mimeApplicationOctetStream = []string{MIMEApplicationOctetStream}
 	mimeMultipartForm          = []string{MIMEMultipartForm}
@@ -69,6 +70,9 @@ func SetContentType(header http.Header, ct string) {
 	case MIMEApplicationMsgpack:
 		header[HeaderContentType] = mimeApplicationMsgpack
 
+	case MIMEApplicationYAML:
+		header[HeaderContentType] = mimeApplicationYAML
+
 	case MIMEApplicationProtobuf:
 		header[HeaderContentType] = mimeApplicationProtobuf
 
@@ -0,0 +1,261 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cache provides a middleware to cache the responses.
+package cache
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xgfone/go-apiserver/http/header"
+	"github.com/xgfone/go-apiserver/http/middleware"
+	"github.com/xgfone/go-apiserver/http/reqresp"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Created    time.Time
+}
+
+// Store is used to store the cached responses, such as a redis backend.
+type Store interface {
+	// Get returns the unexpired cached response by the key.
+	Get(key string) (entry Entry, ok bool)
+
+	// Set caches the response by the key, which expires after ttl.
+	Set(key string, entry Entry, ttl time.Duration)
+}
+
+// Config is used to configure the cache middleware.
+type Config struct {
+	// TTL is the time to live of each cached response.
+	//
+	// Required.
+	TTL time.Duration
+
+	// KeyFunc is used to generate the cache key of the request.
+	//
+	// Optional. Default: DefaultKey.
+	KeyFunc func(*http.Request) string
+
+	// Store is used to store the cached responses.
+	//
+	// Optional. Default: NewMemoryStore(1024).
+	Store Store
+
+	// Vary is the list of the request headers, such as "Accept-Encoding",
+	// whose values are appended to the cache key.
+	//
+	// The response whose header "Vary" contains "*" or any header
+	// not in the list is not cached.
+	//
+	// Optional.
+	Vary []string
+}
+
+// DefaultKey returns the cache key of the request, which is composed of
+// the method, the host, the path and the sorted query.
+func DefaultKey(r *http.Request) string {
+	query := r.URL.RawQuery
+	if query != "" {
+		query = r.URL.Query().Encode() // Encode sorts the query by the key.
+	}
+	return strings.Join([]string{r.Method, strings.ToLower(r.Host), r.URL.Path, query}, " ")
+}
+
+func varyKey(r *http.Request, key string, vary []string) string {
+	if len(vary) == 0 {
+		return key
+	}
+
+	var b strings.Builder
+	b.WriteString(key)
+	for _, name := range vary {
+		b.WriteByte('\n')
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+	return b.String()
+}
+
+func varyCovered(h http.Header, vary []string) bool {
+	for _, value := range h.Values(header.HeaderVary) {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			} else if name == "*" || !slices.ContainsFunc(vary, func(s string) bool {
+				return strings.EqualFold(s, name)
+			}) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Cache returns a new middleware to cache the responses of the GET and HEAD
+// requests, and set the response header "Age" when serving from the cache.
+//
+// Only the responses with the status code 200 are cached. And the request
+// with the header "Authorization" or "Cache-Control: no-store", and the
+// response with "Cache-Control: private" or "Cache-Control: no-store",
+// are not cached.
+//
+// If a *reqresp.Context has been set into the request, its ResponseWriter
+// is replaced during handling the request to capture the response.
+func Cache(name string, priority int, config Config) middleware.Middleware {
+	if config.TTL <= 0 {
+		panic("cache.Cache: the ttl must be greater than 0")
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = DefaultKey
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore(1024)
+	}
+
+	return middleware.New(name, priority, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cacheableRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := varyKey(r, config.KeyFunc(r), config.Vary)
+			if entry, ok := config.Store.Get(key); ok {
+				serveEntry(w, entry)
+				return
+			}
+
+			rw := &responseWriter{ResponseWriter: w, buffer: r.Method == http.MethodGet}
+			if c := reqresp.GetContext(r.Context()); c != nil {
+				orig := c.ResponseWriter
+				rw.ResponseWriter = orig
+				c.ResponseWriter = rw
+				defer func() { c.ResponseWriter = orig }()
+			}
+
+			before := rw.Header().Clone()
+			next.ServeHTTP(rw, r)
+
+			if rw.cacheable() && varyCovered(rw.Header(), config.Vary) {
+				config.Store.Set(key, Entry{
+					StatusCode: rw.code,
+					Header:     diffHeader(before, rw.Header()),
+					Body:       rw.body.Bytes(),
+					Created:    time.Now(),
+				}, config.TTL)
+			}
+		})
+	})
+}
+
+func cacheableRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		return r.Header.Get(header.HeaderAuthorization) == "" &&
+			!hasCacheControl(r.Header, "no-store")
+	default:
+		return false
+	}
+}
+
+func hasCacheControl(h http.Header, directive string) bool {
+	for _, value := range strings.Split(h.Get(header.HeaderCacheControl), ",") {
+		if value = strings.TrimSpace(value); strings.EqualFold(value, directive) ||
+			(len(value) > len(directive) && strings.EqualFold(value[:len(directive)+1], directive+"=")) {
+			return true
+		}
+	}
+	return false
+}
+
+// diffHeader returns the headers set by the downstream handlers,
+// which excludes those set by the outer middlewares before caching.
+func diffHeader(before, after http.Header) http.Header {
+	h := make(http.Header, len(after))
+	for key, values := range after {
+		if !slices.Equal(before[key], values) {
+			h[key] = slices.Clone(values)
+		}
+	}
+	return h
+}
+
+func serveEntry(w http.ResponseWriter, entry Entry) {
+	h := w.Header()
+	for key, values := range entry.Header {
+		h[key] = append([]string(nil), values...)
+	}
+
+	age := int64(time.Since(entry.Created) / time.Second)
+	h.Set(header.HeaderAge, strconv.FormatInt(age, 10))
+	w.WriteHeader(entry.StatusCode)
+	if len(entry.Body) > 0 {
+		_, _ = w.Write(entry.Body)
+	}
+}
+
+var _ reqresp.ResponseWriter = new(responseWriter)
+
+type responseWriter struct {
+	http.ResponseWriter
+	buffer bool
+	body   bytes.Buffer
+	code   int
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+func (w *responseWriter) WroteHeader() bool           { return w.code > 0 }
+func (w *responseWriter) StatusCode() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	if w.buffer && w.code == http.StatusOK {
+		w.body.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *responseWriter) cacheable() bool {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.code == http.StatusOK &&
+		!hasCacheControl(w.Header(), "no-store") &&
+		!hasCacheControl(w.Header(), "private")
+}
@@ -0,0 +1,174 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ctxmw "github.com/xgfone/go-apiserver/http/middleware/context"
+	"github.com/xgfone/go-apiserver/http/reqresp"
+)
+
+func TestCache(t *testing.T) {
+	var count int
+	h := Cache("cache", 0, Config{TTL: time.Minute}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		switch r.URL.Path {
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		case "/error":
+			w.WriteHeader(500)
+			return
+		}
+		w.Header().Set("X-Test", "abc")
+		_, _ = w.Write([]byte("body"))
+	}))
+
+	tests := []struct {
+		method string
+		path   string
+		count  int
+		age    string
+	}{
+		{method: http.MethodGet, path: "/path?b=2&a=1", count: 1, age: ""},
+		{method: http.MethodGet, path: "/path?a=1&b=2", count: 1, age: "0"},
+		{method: http.MethodPost, path: "/path?a=1&b=2", count: 2, age: ""},
+		{method: http.MethodGet, path: "/nostore", count: 3, age: ""},
+		{method: http.MethodGet, path: "/nostore", count: 4, age: ""},
+		{method: http.MethodGet, path: "/error", count: 5, age: ""},
+		{method: http.MethodGet, path: "/error", count: 6, age: ""},
+		{method: http.MethodGet, path: "/private", count: 7, age: ""},
+		{method: http.MethodGet, path: "/private", count: 8, age: ""},
+		{method: http.MethodGet, path: "/auth", count: 9, age: ""},
+		{method: http.MethodGet, path: "/auth", count: 10, age: ""},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(test.method, test.path, nil)
+		if test.path == "/auth" {
+			req.Header.Set("Authorization", "Bearer token")
+		}
+		h.ServeHTTP(rec, req)
+		if count != test.count {
+			t.Errorf("%s %s: expect count %d, but got %d", test.method, test.path, test.count, count)
+		}
+		if age := rec.Header().Get("Age"); age != test.age {
+			t.Errorf("%s %s: expect Age '%s', but got '%s'", test.method, test.path, test.age, age)
+		}
+		if test.path != "/error" {
+			if body := rec.Body.String(); body != "body" {
+				t.Errorf("%s %s: expect body '%s', but got '%s'", test.method, test.path, "body", body)
+			} else if value := rec.Header().Get("X-Test"); value != "abc" && test.path != "/nostore" && test.path != "/private" {
+				t.Errorf("%s %s: expect X-Test '%s', but got '%s'", test.method, test.path, "abc", value)
+			}
+		}
+	}
+}
+
+func TestCacheWithContext(t *testing.T) {
+	var count int
+	h := Cache("cache", 0, Config{TTL: time.Minute}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		c := reqresp.GetContext(r.Context())
+		c.Header().Set("X-Test", "abc")
+		c.Text(200, "body")
+	}))
+	h = ctxmw.Context(h)
+
+	for i := 1; i <= 2; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/path", nil))
+		if count != 1 {
+			t.Errorf("%d: expect count %d, but got %d", i, 1, count)
+		}
+		if body := rec.Body.String(); body != "body" {
+			t.Errorf("%d: expect body '%s', but got '%s'", i, "body", body)
+		}
+		if value := rec.Header().Get("X-Test"); value != "abc" {
+			t.Errorf("%d: expect X-Test '%s', but got '%s'", i, "abc", value)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	var count int
+	h := Cache("cache", 0, Config{TTL: time.Minute, Vary: []string{"Accept-Encoding"}}).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			count++
+			switch r.URL.Path {
+			case "/vary":
+				w.Header().Set("Vary", "Accept-Encoding")
+			case "/novary":
+				w.Header().Set("Vary", "Accept-Encoding, Accept-Language")
+			}
+			_, _ = w.Write([]byte(r.Host))
+		}))
+
+	tests := []struct {
+		host     string
+		path     string
+		encoding string
+		count    int
+	}{
+		{host: "a.example.com", path: "/path", count: 1},
+		{host: "A.example.com", path: "/path", count: 1},
+		{host: "b.example.com", path: "/path", count: 2},
+		{host: "b.example.com", path: "/vary", encoding: "gzip", count: 3},
+		{host: "b.example.com", path: "/vary", encoding: "gzip", count: 3},
+		{host: "b.example.com", path: "/vary", encoding: "br", count: 4},
+		{host: "b.example.com", path: "/novary", count: 5},
+		{host: "b.example.com", path: "/novary", count: 6},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		req.Host = test.host
+		if test.encoding != "" {
+			req.Header.Set("Accept-Encoding", test.encoding)
+		}
+
+		h.ServeHTTP(rec, req)
+		if count != test.count {
+			t.Errorf("%s%s: expect count %d, but got %d", test.host, test.path, test.count, count)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore(2)
+	s.Set("a", Entry{StatusCode: 200}, time.Minute)
+	s.Set("b", Entry{StatusCode: 200}, time.Minute)
+	s.Get("a")
+	s.Set("c", Entry{StatusCode: 200}, time.Minute)
+
+	if _, ok := s.Get("b"); ok {
+		t.Errorf("expect the entry 'b' to be evicted")
+	}
+	if _, ok := s.Get("a"); !ok {
+		t.Errorf("expect the entry 'a' to be cached")
+	}
+
+	s.Set("d", Entry{StatusCode: 200}, -time.Second)
+	if _, ok := s.Get("d"); ok {
+		t.Errorf("expect the entry 'd' to be expired")
+	}
+}
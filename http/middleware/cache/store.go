@@ -0,0 +1,95 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+var _ Store = new(MemoryStore)
+
+// MemoryStore is a LRU cache store based on the memory.
+type MemoryStore struct {
+	lock  sync.Mutex
+	list  *list.List
+	cache map[string]*list.Element
+	cap   int
+}
+
+type memoryEntry struct {
+	key    string
+	entry  Entry
+	expire time.Time
+}
+
+// NewMemoryStore returns a new memory store, which evicts
+// the least recently used response when exceeding capacity.
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		panic("cache.NewMemoryStore: the capacity must be greater than 0")
+	}
+
+	return &MemoryStore{
+		list:  list.New(),
+		cache: make(map[string]*list.Element, capacity),
+		cap:   capacity,
+	}
+}
+
+// Get implements the interface Store.
+func (s *MemoryStore) Get(key string) (entry Entry, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	elem, ok := s.cache[key]
+	if !ok {
+		return
+	}
+
+	me := elem.Value.(*memoryEntry)
+	if !time.Now().Before(me.expire) {
+		s.remove(elem)
+		return Entry{}, false
+	}
+
+	s.list.MoveToFront(elem)
+	return me.entry, true
+}
+
+// Set implements the interface Store.
+func (s *MemoryStore) Set(key string, entry Entry, ttl time.Duration) {
+	me := &memoryEntry{key: key, entry: entry, expire: time.Now().Add(ttl)}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if elem, ok := s.cache[key]; ok {
+		elem.Value = me
+		s.list.MoveToFront(elem)
+		return
+	}
+
+	s.cache[key] = s.list.PushFront(me)
+	if s.list.Len() > s.cap {
+		s.remove(s.list.Back())
+	}
+}
+
+func (s *MemoryStore) remove(elem *list.Element) {
+	s.list.Remove(elem)
+	delete(s.cache, elem.Value.(*memoryEntry).key)
+}
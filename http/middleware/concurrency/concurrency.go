@@ -0,0 +1,106 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package concurrency provides a middleware to limit the number
+// of the in-flight requests.
+package concurrency
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/xgfone/go-apiserver/http/middleware"
+	"github.com/xgfone/go-apiserver/http/reqresp"
+	"github.com/xgfone/go-apiserver/result"
+	"github.com/xgfone/go-apiserver/result/codeint"
+)
+
+var _ middleware.Middleware = new(Limiter)
+
+var errTooManyRequests = codeint.ErrServiceUnavailable.WithMessage("too many in-flight requests")
+
+// Limiter is a named priority middleware to limit the number
+// of the in-flight requests.
+type Limiter struct {
+	name     string
+	priority int
+	timeout  time.Duration
+	sem      chan struct{}
+}
+
+// Concurrency returns a new middleware to limit the number of
+// the in-flight requests to max.
+//
+// If reaching max, the request is queued up to queueTimeout,
+// then responds 503 with the error codeint.ErrServiceUnavailable.
+// If queueTimeout is equal to or less than 0, respond it immediately.
+func Concurrency(name string, priority int, max int, queueTimeout time.Duration) *Limiter {
+	if max <= 0 {
+		panic("concurrency.Concurrency: the maximum number must be greater than 0")
+	}
+
+	return &Limiter{
+		name:     name,
+		priority: priority,
+		timeout:  queueTimeout,
+		sem:      make(chan struct{}, max),
+	}
+}
+
+// Name returns the name of the middleware.
+func (l *Limiter) Name() string { return l.name }
+
+// Priority returns the priority of the middleware.
+func (l *Limiter) Priority() int { return l.priority }
+
+// InFlight returns the number of the current in-flight requests.
+func (l *Limiter) InFlight() int { return len(l.sem) }
+
+// Handler implements the interface middleware.Middleware.
+func (l *Limiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.acquire(r) {
+			reqresp.DefaultRespond(w, r, result.Err(errTooManyRequests))
+			return
+		}
+
+		defer l.release()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (l *Limiter) acquire(r *http.Request) bool {
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+		if l.timeout <= 0 {
+			return false
+		}
+	}
+
+	timer := time.NewTimer(l.timeout)
+	defer timer.Stop()
+
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+func (l *Limiter) release() { <-l.sem }
@@ -0,0 +1,70 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package concurrency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConcurrency(t *testing.T) {
+	l := Concurrency("concurrency", 0, 1, time.Millisecond*10)
+
+	start := make(chan struct{})
+	stop := make(chan struct{})
+	h := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" {
+			panic("test")
+		}
+
+		close(start)
+		<-stop
+		w.WriteHeader(204)
+	}))
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		done <- rec.Code
+	}()
+
+	<-start
+	if n := l.InFlight(); n != 1 {
+		t.Errorf("expect %d in-flight requests, but got %d", 1, n)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != 503 {
+		t.Errorf("expect status code %d, but got %d", 503, rec.Code)
+	}
+
+	close(stop)
+	if code := <-done; code != 204 {
+		t.Errorf("expect status code %d, but got %d", 204, code)
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	}()
+
+	if n := l.InFlight(); n != 0 {
+		t.Errorf("expect %d in-flight requests, but got %d", 0, n)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package headers provides a middleware to set the constant headers
+// of all the requests and responses.
+package headers
+
+import (
+	"net/http"
+
+	"github.com/xgfone/go-apiserver/http/middleware"
+)
+
+// Config is used to configure the headers to be set or added.
+type Config struct {
+	// SetRequest and AddRequest are the request headers to be set or added.
+	SetRequest map[string]string
+	AddRequest map[string]string
+
+	// SetResponse and AddResponse are the response headers to be set or added.
+	SetResponse map[string]string
+	AddResponse map[string]string
+}
+
+// SetHeaders returns a new middleware to set or add the request headers
+// and the response headers before calling the next handler.
+//
+// The headers in SetRequest and SetResponse overwrite the existed values,
+// and those in AddRequest and AddResponse are added to the existed values.
+// The Set headers are applied before the Add ones.
+//
+// Because the response headers are applied before calling the next handler,
+// they may be changed or overwritten by the next handler.
+func SetHeaders(name string, priority int, config Config) middleware.Middleware {
+	reqs := newHeaders(config.SetRequest, config.AddRequest)
+	resps := newHeaders(config.SetResponse, config.AddResponse)
+	return middleware.New(name, priority, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(reqs) > 0 {
+				if r.Header == nil {
+					r.Header = make(http.Header, len(reqs))
+				}
+				reqs.apply(r.Header)
+			}
+
+			if len(resps) > 0 {
+				resps.apply(w.Header())
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+type headerValue struct {
+	key   string
+	value string
+	add   bool
+}
+
+type headers []headerValue
+
+func newHeaders(sets, adds map[string]string) headers {
+	hs := make(headers, 0, len(sets)+len(adds))
+	hs = hs.append(sets, false)
+	hs = hs.append(adds, true)
+	return hs
+}
+
+func (hs headers) append(m map[string]string, add bool) headers {
+	for key, value := range m {
+		if key == "" {
+			panic("headers.SetHeaders: the header key must not be empty")
+		}
+		hs = append(hs, headerValue{key: http.CanonicalHeaderKey(key), value: value, add: add})
+	}
+	return hs
+}
+
+func (hs headers) apply(h http.Header) {
+	for _, hv := range hs {
+		if hv.add {
+			h.Add(hv.key, hv.value)
+		} else {
+			h.Set(hv.key, hv.value)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package headers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	ctxmw "github.com/xgfone/go-apiserver/http/middleware/context"
+	"github.com/xgfone/go-apiserver/http/reqresp"
+)
+
+func TestSetHeaders(t *testing.T) {
+	h := SetHeaders("headers", 0, Config{
+		SetRequest:  map[string]string{"X-Req": "abc"},
+		AddRequest:  map[string]string{"Accept": "text/plain"},
+		SetResponse: map[string]string{"x-resp": "xyz"},
+		AddResponse: map[string]string{"Vary": "Origin"},
+	}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if value := r.Header.Get("X-Req"); value != "abc" {
+			t.Errorf("expect request header '%s', but got '%s'", "abc", value)
+		}
+		if values := r.Header.Values("Accept"); !slices.Equal(values, []string{"application/json", "text/plain"}) {
+			t.Errorf("expect Accept %v, but got %v", []string{"application/json", "text/plain"}, values)
+		}
+
+		w.Header().Add("Vary", "Accept")
+		if r.URL.Path == "/override" {
+			w.Header().Set("X-Resp", "handler")
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/json")
+	h.ServeHTTP(rec, req)
+
+	if value := rec.Header().Get("X-Resp"); value != "xyz" {
+		t.Errorf("expect response header '%s', but got '%s'", "xyz", value)
+	}
+	if values := rec.Header().Values("Vary"); !slices.Equal(values, []string{"Origin", "Accept"}) {
+		t.Errorf("expect Vary %v, but got %v", []string{"Origin", "Accept"}, values)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/override", nil)
+	req.Header.Set("Accept", "application/json")
+	h.ServeHTTP(rec, req)
+	if value := rec.Header().Get("X-Resp"); value != "handler" {
+		t.Errorf("expect response header '%s', but got '%s'", "handler", value)
+	}
+}
+
+func TestSetHeadersWithContext(t *testing.T) {
+	h := SetHeaders("headers", 0, Config{
+		SetResponse: map[string]string{"X-Resp": "xyz"},
+	}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqresp.GetContext(r.Context()).Text(200, "body")
+	}))
+	h = ctxmw.Context(h)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if value := rec.Header().Get("X-Resp"); value != "xyz" {
+		t.Errorf("expect response header '%s', but got '%s'", "xyz", value)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package host provides a middleware to validate the request host.
+package host
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/xgfone/go-apiserver/http/middleware"
+	"github.com/xgfone/go-apiserver/http/reqresp"
+	"github.com/xgfone/go-apiserver/result"
+	"github.com/xgfone/go-apiserver/result/codeint"
+	matcher "github.com/xgfone/go-http-matcher"
+)
+
+// AllowedHosts returns a new middleware to reject the request whose host
+// is not in the allowed hosts, which mitigates the host header injection.
+//
+// The host supports the exact or wildcard domain, such as "www.example.com"
+// or "*.example.com", and the request host is extracted by matcher.GetHost.
+// The wildcard domain only matches the subdomains, such as "api.example.com",
+// but not "example.com" or "evilexample.com". "*" matches any host.
+// Other wildcard forms, such as "*example.com" or "api.*.com", will panic.
+func AllowedHosts(name string, priority int, hosts ...string) middleware.Middleware {
+	if len(hosts) == 0 {
+		panic("host.AllowedHosts: the allowed hosts must not be empty")
+	}
+
+	matches := make([]func(string) bool, len(hosts))
+	for i, host := range hosts {
+		if host == "" {
+			panic("host.AllowedHosts: the allowed host must not be empty")
+		}
+		matches[i] = buildHostMatcher(strings.ToLower(host))
+	}
+
+	return middleware.New(name, priority, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host := matcher.GetHost(r)
+			for _, match := range matches {
+				if match(host) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			err := codeint.ErrBadRequest.WithMessagef("host '%s' is not allowed", host)
+			reqresp.DefaultRespond(w, r, result.Err(err))
+		})
+	})
+}
+
+func buildHostMatcher(host string) func(string) bool {
+	switch {
+	case host == "*":
+		return func(string) bool { return true }
+
+	case strings.HasPrefix(host, "*."):
+		suffix := host[2:]
+		if suffix == "" || strings.Contains(suffix, "*") {
+			panic(fmt.Errorf("host.AllowedHosts: invalid wildcard host '%s'", host))
+		}
+
+		suffix = "." + suffix
+		return func(s string) bool { return strings.HasSuffix(s, suffix) }
+
+	case strings.Contains(host, "*"):
+		panic(fmt.Errorf("host.AllowedHosts: invalid wildcard host '%s', only the prefix '*.' is supported", host))
+
+	default:
+		return func(s string) bool { return s == host }
+	}
+}
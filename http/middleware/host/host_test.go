@@ -0,0 +1,63 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xgfone/go-apiserver/http/handler"
+)
+
+func TestAllowedHosts(t *testing.T) {
+	h := AllowedHosts("host", 0, "www.example.com", "*.example.org").Handler(handler.Handler204)
+
+	tests := []struct {
+		host string
+		code int
+	}{
+		{host: "www.example.com", code: 204},
+		{host: "WWW.Example.com:8080", code: 204},
+		{host: "api.example.org", code: 204},
+		{host: "a.b.example.org", code: 204},
+		{host: "example.org", code: 400},
+		{host: "evil.com", code: 400},
+		{host: "evilexample.org", code: 400},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = test.host
+		h.ServeHTTP(rec, req)
+		if rec.Code != test.code {
+			t.Errorf("host '%s': expect status code %d, but got %d", test.host, test.code, rec.Code)
+		}
+	}
+}
+
+func TestAllowedHostsInvalidWildcard(t *testing.T) {
+	for _, host := range []string{"*example.com", "*.", "api.*.com", "*.*.com"} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("host '%s': expect a panic, but got nil", host)
+				}
+			}()
+			AllowedHosts("host", 0, host)
+		}()
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maintenance provides a middleware to switch the maintenance mode
+// at runtime.
+package maintenance
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/xgfone/go-apiserver/http/header"
+	"github.com/xgfone/go-apiserver/http/middleware"
+	"github.com/xgfone/go-apiserver/http/reqresp"
+	"github.com/xgfone/go-apiserver/result"
+	"github.com/xgfone/go-apiserver/result/codeint"
+)
+
+var errMaintenance = codeint.ErrServiceUnavailable.WithMessage("the service is under maintenance")
+
+// Option is used to configure the maintenance middleware.
+type Option func(*options)
+
+type options struct {
+	allowedPaths []string
+	retryAfter   string
+}
+
+// AllowPaths returns an option to allow the requests whose paths are
+// in paths, such as "/health", to pass through in the maintenance mode.
+func AllowPaths(paths ...string) Option {
+	return func(o *options) { o.allowedPaths = append(o.allowedPaths, paths...) }
+}
+
+// RetryAfter returns an option to set the response header "Retry-After"
+// to d in the maintenance mode.
+//
+// If d is equal to or less than 0, the header is not set, which is the default.
+func RetryAfter(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.retryAfter = strconv.FormatInt(int64(d/time.Second), 10)
+		} else {
+			o.retryAfter = ""
+		}
+	}
+}
+
+// Maintenance returns a new middleware and the flag of the maintenance mode.
+//
+// When the flag is set to true, the middleware responds 503 with
+// the error codeint.ErrServiceUnavailable for all the requests,
+// except those allowed by the option AllowPaths.
+// The flag is safe to be flipped concurrently while serving.
+func Maintenance(name string, priority int, opts ...Option) (middleware.Middleware, *atomic.Bool) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	enabled := new(atomic.Bool)
+	return middleware.New(name, priority, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !enabled.Load() || slices.Contains(o.allowedPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if o.retryAfter != "" {
+				w.Header().Set(header.HeaderRetryAfter, o.retryAfter)
+			}
+			reqresp.DefaultRespond(w, r, result.Err(errMaintenance))
+		})
+	}), enabled
+}
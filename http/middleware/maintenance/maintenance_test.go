@@ -0,0 +1,62 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-apiserver/http/handler"
+)
+
+func TestMaintenance(t *testing.T) {
+	mw, enabled := Maintenance("maintenance", 0, AllowPaths("/health"), RetryAfter(time.Minute))
+	h := mw.Handler(handler.Handler204)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/path", nil))
+	if rec.Code != 204 {
+		t.Errorf("expect status code %d, but got %d", 204, rec.Code)
+	}
+
+	enabled.Store(true)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/path", nil))
+	if rec.Code != 503 {
+		t.Errorf("expect status code %d, but got %d", 503, rec.Code)
+	} else if retry := rec.Header().Get("Retry-After"); retry != "60" {
+		t.Errorf("expect Retry-After '%s', but got '%s'", "60", retry)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != 204 {
+		t.Errorf("expect status code %d, but got %d", 204, rec.Code)
+	}
+
+	mw, enabled = Maintenance("maintenance", 0)
+	enabled.Store(true)
+
+	rec = httptest.NewRecorder()
+	mw.Handler(handler.Handler204).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/path", nil))
+	if rec.Code != 503 {
+		t.Errorf("expect status code %d, but got %d", 503, rec.Code)
+	} else if retry := rec.Header().Get("Retry-After"); retry != "" {
+		t.Errorf("expect no Retry-After, but got '%s'", retry)
+	}
+}
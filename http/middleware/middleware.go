@@ -24,6 +24,7 @@ import (
 	"github.com/xgfone/go-apiserver/http/middleware/path"
 	"github.com/xgfone/go-apiserver/http/middleware/recover"
 	"github.com/xgfone/go-apiserver/http/middleware/requestid"
+	"github.com/xgfone/go-apiserver/http/reqresp"
 )
 
 var (
@@ -156,6 +157,34 @@ func (m *middleware) Name() string                           { return m.n }
 func (m *middleware) Priority() int                          { return m.p }
 func (m *middleware) Handler(next http.Handler) http.Handler { return m.f(next) }
 
+// StopIfWritten wraps the middleware inner and returns a new one,
+// which does not call the next handler any more if inner has written
+// the response header, such as an auth middleware responding early.
+//
+// The written state is checked by reqresp.WroteHeader,
+// and the name and priority of inner are inherited if it has implemented
+//
+//	interface{ Name() string }
+//	interface{ Priority() int }
+func StopIfWritten(inner Middleware) Middleware {
+	if inner == nil {
+		panic("Middleware.StopIfWritten: the inner middleware must not be nil")
+	}
+	return stopIfWritten{inner: inner}
+}
+
+type stopIfWritten struct{ inner Middleware }
+
+func (m stopIfWritten) Name() string  { return GetName(m.inner) }
+func (m stopIfWritten) Priority() int { return GetPriority(m.inner) }
+func (m stopIfWritten) Handler(next http.Handler) http.Handler {
+	return m.inner.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !reqresp.WroteHeader(w) {
+			next.ServeHTTP(w, r)
+		}
+	}))
+}
+
 // Sort sorts a set of middlewares by the priority from high to low.
 func Sort(ms []Middleware) {
 	slices.SortStableFunc(ms, func(a, b Middleware) int {
@@ -177,6 +206,18 @@ func GetPriority(m Middleware) int {
 	return 0
 }
 
+// GetName returns the name of the middleware if it has implemented
+//
+//	interface{ Name() string }
+//
+// Or, return "" instead.
+func GetName(m Middleware) string {
+	if n, ok := m.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return ""
+}
+
 func mergeMiddlewares(mws1, mws2 Middlewares) Middlewares {
 	len1, len2 := len(mws1), len(mws2)
 	switch {
@@ -19,6 +19,8 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+
+	"github.com/xgfone/go-apiserver/http/reqresp"
 )
 
 func TestMiddlewares(t *testing.T) {
@@ -69,3 +71,46 @@ func TestNamedPriorityMiddleware(t *testing.T) {
 		t.Errorf("expect %v, but got %v", expects, names)
 	}
 }
+
+func TestStopIfWritten(t *testing.T) {
+	inner := New("auth", 5, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				w.WriteHeader(401)
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	m := StopIfWritten(inner)
+	if prio := GetPriority(m); prio != 5 {
+		t.Errorf("expect priority %d, but got %d", 5, prio)
+	}
+	if name := GetName(m); name != "auth" {
+		t.Errorf("expect name '%s', but got '%s'", "auth", name)
+	}
+
+	var called bool
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(204)
+	}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/path", nil)
+	h.ServeHTTP(reqresp.AcquireResponseWriter(rec), req)
+	if called {
+		t.Errorf("unexpect the next handler to be called")
+	} else if rec.Code != 401 {
+		t.Errorf("expect status code %d, but got %d", 401, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req.Header.Set("Authorization", "token")
+	h.ServeHTTP(reqresp.AcquireResponseWriter(rec), req)
+	if !called {
+		t.Errorf("expect the next handler to be called, but got not")
+	} else if rec.Code != 204 {
+		t.Errorf("expect status code %d, but got %d", 204, rec.Code)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nonce provides a nonce middleware to reject the replayed requests.
+package nonce
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-apiserver/http/middleware"
+	"github.com/xgfone/go-apiserver/http/reqresp"
+	"github.com/xgfone/go-apiserver/result"
+	"github.com/xgfone/go-apiserver/result/codeint"
+)
+
+// DefaultHeader is the default request header to carry the nonce.
+var DefaultHeader = "X-Nonce"
+
+var (
+	errMissingNonce = codeint.ErrUnauthorized.WithMessage("missing the nonce")
+	errReplayNonce  = codeint.ErrUnauthorized.WithMessage("the nonce has been used")
+)
+
+// Store is used to record the seen nonces.
+type Store interface {
+	// Add records the nonce and reports whether it is seen for the first time.
+	//
+	// If the nonce has been recorded and not expired, return false.
+	Add(nonce string) (ok bool)
+}
+
+// Nonce returns a new middleware to reject the request which has no nonce
+// in the request header or whose nonce has been seen by store.
+//
+// If header is empty, use DefaultHeader instead.
+func Nonce(name string, priority int, store Store, header string) middleware.Middleware {
+	if store == nil {
+		panic("nonce.Nonce: the nonce store must not be nil")
+	}
+
+	if header == "" {
+		header = DefaultHeader
+	}
+	header = http.CanonicalHeaderKey(header)
+
+	return middleware.New(name, priority, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var nonce string
+			if values := r.Header[header]; len(values) > 0 {
+				nonce = values[0]
+			}
+
+			switch {
+			case nonce == "":
+				reqresp.DefaultRespond(w, r, result.Err(errMissingNonce))
+			case !store.Add(nonce):
+				reqresp.DefaultRespond(w, r, result.Err(errReplayNonce))
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	})
+}
+
+// MemoryStore is a nonce store based on the memory, which is only suitable
+// for a single instance since the nonces are not shared across processes.
+//
+// Each nonce is remembered for ttl since it is added, and rejected
+// as the replay within the period. The expired nonces are evicted
+// lazily by Add at most once every ttl, so the memory is bounded
+// by the number of the nonces added in about two ttl periods.
+type MemoryStore struct {
+	ttl   time.Duration
+	lock  sync.Mutex
+	next  time.Time
+	cache map[string]time.Time
+}
+
+// NewMemoryStore returns a new memory nonce store,
+// which will forget the nonce after ttl.
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	if ttl <= 0 {
+		panic("nonce.NewMemoryStore: the ttl must be greater than 0")
+	}
+	return &MemoryStore{ttl: ttl, cache: make(map[string]time.Time, 64)}
+}
+
+// Add implements the interface Store.
+func (s *MemoryStore) Add(nonce string) (ok bool) {
+	now := time.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if now.After(s.next) {
+		s.clean(now)
+		s.next = now.Add(s.ttl)
+	}
+
+	if expire, exist := s.cache[nonce]; exist && now.Before(expire) {
+		return false
+	}
+
+	s.cache[nonce] = now.Add(s.ttl)
+	return true
+}
+
+func (s *MemoryStore) clean(now time.Time) {
+	for nonce, expire := range s.cache {
+		if !now.Before(expire) {
+			delete(s.cache, nonce)
+		}
+	}
+}
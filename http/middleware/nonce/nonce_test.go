@@ -0,0 +1,76 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nonce
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-apiserver/http/handler"
+)
+
+func TestNonce(t *testing.T) {
+	h := Nonce("nonce", 0, NewMemoryStore(time.Minute), "").Handler(handler.Handler204)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	h.ServeHTTP(rec, req)
+	if rec.Code != 401 {
+		t.Errorf("expect status code %d, but got %d", 401, rec.Code)
+	}
+
+	req.Header.Set("X-Nonce", "abc")
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != 204 {
+		t.Errorf("expect status code %d, but got %d", 204, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != 401 {
+		t.Errorf("expect status code %d, but got %d", 401, rec.Code)
+	}
+}
+
+func TestNonceHeader(t *testing.T) {
+	h := Nonce("nonce", 0, NewMemoryStore(time.Minute), "x-request-nonce").Handler(handler.Handler204)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-Request-Nonce", "abc")
+	h.ServeHTTP(rec, req)
+	if rec.Code != 204 {
+		t.Errorf("expect status code %d, but got %d", 204, rec.Code)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore(time.Millisecond * 10)
+	if !store.Add("abc") {
+		t.Errorf("expect the nonce '%s' to be added, but got not", "abc")
+	}
+	if store.Add("abc") {
+		t.Errorf("unexpect the nonce '%s' to be added", "abc")
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	if !store.Add("abc") {
+		t.Errorf("expect the expired nonce '%s' to be added, but got not", "abc")
+	}
+}
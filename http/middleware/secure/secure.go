@@ -0,0 +1,86 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secure provides a middleware to set the security response headers.
+package secure
+
+import (
+	"net/http"
+
+	"github.com/xgfone/go-apiserver/http/header"
+	"github.com/xgfone/go-apiserver/http/middleware"
+)
+
+// DefaultConfig is the default configuration with the common protective values.
+var DefaultConfig = Config{
+	ContentTypeOptions:      "nosniff",
+	FrameOptions:            "SAMEORIGIN",
+	ReferrerPolicy:          "strict-origin-when-cross-origin",
+	StrictTransportSecurity: "max-age=31536000; includeSubDomains",
+}
+
+// Config is used to configure the security headers middleware.
+//
+// If a field is empty, the corresponding response header is omitted.
+type Config struct {
+	// ContentTypeOptions is the value of the header "X-Content-Type-Options".
+	//
+	// Optional. Such as "nosniff".
+	ContentTypeOptions string `json:"contentTypeOptions" yaml:"contentTypeOptions"`
+
+	// FrameOptions is the value of the header "X-Frame-Options".
+	//
+	// Optional. Such as "DENY" or "SAMEORIGIN".
+	FrameOptions string `json:"frameOptions" yaml:"frameOptions"`
+
+	// ReferrerPolicy is the value of the header "Referrer-Policy".
+	//
+	// Optional. Such as "no-referrer" or "strict-origin-when-cross-origin".
+	ReferrerPolicy string `json:"referrerPolicy" yaml:"referrerPolicy"`
+
+	// StrictTransportSecurity is the value of the header
+	// "Strict-Transport-Security", which is only set over HTTPS.
+	//
+	// Optional. Such as "max-age=31536000; includeSubDomains".
+	StrictTransportSecurity string `json:"strictTransportSecurity" yaml:"strictTransportSecurity"`
+
+	// ContentSecurityPolicy is the value of the header "Content-Security-Policy".
+	//
+	// Optional. Such as "default-src 'self'".
+	ContentSecurityPolicy string `json:"contentSecurityPolicy" yaml:"contentSecurityPolicy"`
+}
+
+// SecureHeaders returns a new middleware to set the security response
+// headers configured by config before calling the next handler.
+func SecureHeaders(name string, priority int, config Config) middleware.Middleware {
+	return middleware.New(name, priority, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			setHeader(h, header.HeaderXContentTypeOptions, config.ContentTypeOptions)
+			setHeader(h, header.HeaderXFrameOptions, config.FrameOptions)
+			setHeader(h, header.HeaderReferrerPolicy, config.ReferrerPolicy)
+			setHeader(h, header.HeaderContentSecurityPolicy, config.ContentSecurityPolicy)
+			if r.TLS != nil || header.Scheme(r.Header) == "https" {
+				setHeader(h, header.HeaderStrictTransportSecurity, config.StrictTransportSecurity)
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+func setHeader(h http.Header, key, value string) {
+	if value != "" {
+		h.Set(key, value)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xgfone/go-apiserver/http/handler"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	config := DefaultConfig
+	config.FrameOptions = ""
+	config.ContentSecurityPolicy = "default-src 'self'"
+	h := SecureHeaders("secure", 0, config).Handler(handler.Handler204)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+
+	expects := map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+		"Content-Security-Policy":   "default-src 'self'",
+		"Strict-Transport-Security": "",
+	}
+	for key, expect := range expects {
+		if value := rec.Header().Get(key); value != expect {
+			t.Errorf("%s: expect '%s', but got '%s'", key, expect, value)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "https://localhost/", nil))
+	if value := rec.Header().Get("Strict-Transport-Security"); value != DefaultConfig.StrictTransportSecurity {
+		t.Errorf("expect '%s', but got '%s'", DefaultConfig.StrictTransportSecurity, value)
+	}
+}
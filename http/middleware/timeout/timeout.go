@@ -0,0 +1,63 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package timeout provides a middleware to limit the time to handle a request.
+package timeout
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/xgfone/go-apiserver/http/middleware"
+	"github.com/xgfone/go-apiserver/http/reqresp"
+	"github.com/xgfone/go-apiserver/result"
+	"github.com/xgfone/go-apiserver/result/codeint"
+)
+
+// Timeout returns a new middleware to cancel the request context
+// after timeout.
+//
+// If the handler overruns and has not written the response,
+// respond the error codeint.ErrGatewayTimeout, that's, 504.
+//
+// Notice: unlike http.TimeoutHandler, the handler runs synchronously
+// in the current goroutine, because the pooled reqresp.Context is not
+// goroutine-safe, and Timeout only sets the deadline of the request context.
+// So the handler that does not watch the request context is not interrupted,
+// and 504 is responded only after it returns.
+func Timeout(name string, priority int, timeout time.Duration) middleware.Middleware {
+	if timeout <= 0 {
+		panic("timeout.Timeout: the timeout must be greater than 0")
+	}
+
+	return middleware.New(name, priority, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			r = r.WithContext(ctx)
+			if c := reqresp.GetContext(ctx); c != nil {
+				req := c.Request
+				c.Request = r
+				defer func() { c.Request = req }()
+			}
+
+			next.ServeHTTP(w, r)
+			if ctx.Err() == context.DeadlineExceeded && !reqresp.WroteHeader(w) {
+				reqresp.DefaultRespond(w, r, result.Err(codeint.ErrGatewayTimeout))
+			}
+		})
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timeout
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-apiserver/http/reqresp"
+)
+
+func TestTimeout(t *testing.T) {
+	handler := Timeout("timeout", 0, time.Millisecond*10).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(204)
+	}))
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{path: "/fast", code: 204},
+		{path: "/slow", code: 504},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		rw := reqresp.AcquireResponseWriter(rec)
+		handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, test.path, nil))
+		reqresp.ReleaseResponseWriter(rw)
+
+		if rec.Code != test.code {
+			t.Errorf("%s: expect status code %d, but got %d", test.path, test.code, rec.Code)
+		}
+	}
+}
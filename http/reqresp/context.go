@@ -16,15 +16,18 @@ package reqresp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"mime"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
@@ -32,6 +35,7 @@ import (
 	"github.com/xgfone/go-apiserver/http/handler"
 	"github.com/xgfone/go-apiserver/http/header"
 	"github.com/xgfone/go-apiserver/result"
+	"github.com/xgfone/go-apiserver/result/codeint"
 	"github.com/xgfone/go-binder"
 	"github.com/xgfone/go-defaults"
 	"github.com/xgfone/go-toolkit/unsafex"
@@ -124,6 +128,9 @@ type Context struct {
 	// Query and Cookies are used to cache the parsed request query and cookies.
 	Cookies []*http.Cookie
 	Query   url.Values
+
+	pkeys []string        // the keys of the path parameters in Data
+	errs  []codeint.Error // the errors appended by AppendCodeError
 }
 
 // NewContext returns a new Context.
@@ -134,6 +141,17 @@ func NewContext(dataCapSize int) *Context {
 // Reset resets the context itself.
 func (c *Context) Reset() {
 	clear(c.Data)
+	c.ResetKeepData()
+}
+
+// ResetKeepData is the same as Reset, but does not clear Data,
+// which is used to reuse the context with the pre-seeded data values,
+// such as across the sub-requests.
+//
+// Notice: the data values are kept until Reset is called, so the context
+// should not be released into the pool by ReleaseContext with the large
+// or sensitive data values that should not be shared by other requests.
+func (c *Context) ResetKeepData() {
 	*c = Context{
 		Data: c.Data,
 
@@ -191,6 +209,54 @@ func (c *Context) BindHeader(v any) (err error) {
 	return
 }
 
+// BindPath extracts the path parameters set by SetPathParam,
+// assigns them to v by the tag "path", and validates it like BindQuery.
+func (c *Context) BindPath(v any) (err error) {
+	params := make(map[string]string, len(c.pkeys))
+	for _, key := range c.pkeys {
+		params[key], _ = c.Data[key].(string)
+	}
+
+	if err = binder.BindStructToStringMap(v, "path", params); err == nil {
+		err = defaults.ValidateStruct(v)
+	}
+	return
+}
+
+// SetPathParam sets the path parameter into Data,
+// and records the key to distinguish it from other data for BindPath.
+func (c *Context) SetPathParam(key, value string) {
+	if !slices.Contains(c.pkeys, key) {
+		c.pkeys = append(c.pkeys, key)
+	}
+	c.Data[key] = value
+}
+
+// ReadBody reads and returns the raw request body, which is distinct from
+// the binding methods, such as the body passthrough or signature check.
+//
+// If the request body exceeds max bytes, return codeint.ErrBadRequest.
+// If max is equal to or less than 0, there is no limit.
+func (c *Context) ReadBody(max int64) (data []byte, err error) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return
+	} else if max <= 0 {
+		return io.ReadAll(c.Request.Body)
+	} else if c.Request.ContentLength > max {
+		return nil, errBodyTooLarge(max)
+	}
+
+	data, err = io.ReadAll(io.LimitReader(c.Request.Body, max+1))
+	if err == nil && int64(len(data)) > max {
+		data, err = nil, errBodyTooLarge(max)
+	}
+	return
+}
+
+func errBodyTooLarge(max int64) error {
+	return codeint.ErrBadRequest.WithMessagef("the request body exceeds %d bytes", max)
+}
+
 // ---------------------------------------------------------------------------
 // Request Information
 // ---------------------------------------------------------------------------
@@ -233,6 +299,56 @@ func (c *Context) Scheme() string {
 	return header.Scheme(c.Request.Header)
 }
 
+// NoDeadline is returned by TimeLeft when the request has no deadline.
+const NoDeadline = time.Duration(math.MaxInt64)
+
+// Deadline returns the deadline of the request context,
+// such as the one set by the timeout middleware.
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	return c.Request.Context().Deadline()
+}
+
+// TimeLeft returns the remaining time before the deadline of the request
+// context, which is negative if the deadline has passed.
+//
+// If the request has no deadline, return NoDeadline.
+func (c *Context) TimeLeft() time.Duration {
+	if deadline, ok := c.Deadline(); ok {
+		return time.Until(deadline)
+	}
+	return NoDeadline
+}
+
+// GetHeaderInt64 returns the value as int64 by the key from the request header.
+//
+// If the header does not exist and required is false, return (0, nil).
+func (c *Context) GetHeaderInt64(key string, required bool) (value int64, err error) {
+	if v := c.Request.Header.Get(key); v != "" {
+		value, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			err = fmt.Errorf("invalid header '%s': %s", key, err)
+		}
+	} else if required {
+		err = fmt.Errorf("missing %s", key)
+	}
+	return
+}
+
+// GetHeaderBool returns the value as bool by the key from the request header.
+//
+// If the header does not exist and required is false, return (false, nil).
+func (c *Context) GetHeaderBool(key string, required bool) (value bool, err error) {
+	if v := c.Request.Header.Get(key); v != "" {
+		value, err = strconv.ParseBool(v)
+		if err != nil {
+			err = fmt.Errorf("invalid header '%s': %s", key, err)
+		}
+	} else if required {
+		err = fmt.Errorf("missing %s", key)
+	}
+	return
+}
+
 // ---------------------------------------------------------------------------
 // Data
 // ---------------------------------------------------------------------------
@@ -355,6 +471,16 @@ func (c *Context) GetQueryUint64(key string, required bool) (value uint64, err e
 // Request Path
 // ---------------------------------------------------------------------------
 
+// GetPathString returns the path value by the path argument key.
+//
+// If the key does not exist, return ("", false) instead of panicking.
+func (c *Context) GetPathString(key string) (value string, ok bool) {
+	if _, ok = c.Data[key]; ok {
+		value = c.getDataString(key, false)
+	}
+	return
+}
+
 // GetPathInt64 returns the path value as int64 by the path argument key.
 //
 // If the key does not exist, it will panic.
@@ -377,6 +503,28 @@ func (c *Context) GetPathUint64(key string) (value uint64, err error) {
 	return
 }
 
+// GetPathFloat64 returns the path value as float64 by the path argument key.
+//
+// If the key does not exist, it will panic.
+func (c *Context) GetPathFloat64(key string) (value float64, err error) {
+	value, err = strconv.ParseFloat(c.MustGetDataString(key), 64)
+	if err != nil {
+		err = fmt.Errorf("invalid path '%s': %s", key, err)
+	}
+	return
+}
+
+// GetPathBool returns the path value as bool by the path argument key.
+//
+// If the key does not exist, it will panic.
+func (c *Context) GetPathBool(key string) (value bool, err error) {
+	value, err = strconv.ParseBool(c.MustGetDataString(key))
+	if err != nil {
+		err = fmt.Errorf("invalid path '%s': %s", key, err)
+	}
+	return
+}
+
 // ---------------------------------------------------------------------------
 // Request Cookie
 // ---------------------------------------------------------------------------
@@ -417,6 +565,31 @@ func (c *Context) AppendError(err error) {
 	}
 }
 
+// AppendCodeError collects the error tagged with the code, such as
+// the invalid field, which can be got by Errors and responded by RespondErrors.
+//
+// Like codeint.Error, the code is an integer, such as 400 or 10001.
+// Unlike AppendError, it does not change c.Err.
+func (c *Context) AppendCodeError(code int, err error) {
+	if err != nil {
+		c.errs = append(c.errs, codeint.NewError(code).WithError(err))
+	}
+}
+
+// Errors returns a copy of the errors collected by AppendCodeError.
+func (c *Context) Errors() []codeint.Error { return slices.Clone(c.errs) }
+
+// RespondErrors responds the errors collected by AppendCodeError
+// as the data of codeint.ErrBadRequest, that's, 400, and returns true.
+//
+// If no errors are collected, it does nothing and returns false.
+func (c *Context) RespondErrors() (responded bool) {
+	if responded = len(c.errs) > 0; responded {
+		c.Respond(result.Err(codeint.ErrBadRequest.WithData(c.Errors())))
+	}
+	return
+}
+
 // SetConnectionClose sets the response header "Content-Disposition".
 // For example,
 //
@@ -456,6 +629,26 @@ func (c *Context) SetContentType(ct string) {
 	header.SetContentType(c.ResponseWriter.Header(), ct)
 }
 
+// SetCacheFor sets the response headers "Cache-Control" and "Expires"
+// to let the response be cached for the duration d. For example,
+//
+//	Cache-Control: public, max-age=3600
+//	Expires: Mon, 02 Jan 2006 16:04:05 GMT
+//
+// If d is less than 0, it is equal to 0.
+func (c *Context) SetCacheFor(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	maxage := int64(d / time.Second)
+	expires := time.Now().Add(time.Duration(maxage) * time.Second)
+
+	h := c.ResponseWriter.Header()
+	h.Set(header.HeaderCacheControl, "public, max-age="+strconv.FormatInt(maxage, 10))
+	h.Set(header.HeaderExpires, expires.UTC().Format(http.TimeFormat))
+}
+
 // NoContent is the alias of WriteHeader.
 func (c *Context) NoContent(code int) { c.WriteHeader(code) }
 
@@ -488,9 +681,56 @@ func (c *Context) Error(err error) {
 	}
 }
 
+// ErrorNegotiated is the same as Error, but renders the error as JSON or XML
+// by the request header "Accept", which uses JSON by default.
+//
+// For JSON, the error is responded by Respond like other errors.
+// For XML, the status code is resolved like RespondErrorWithContextByCode,
+// but it falls back to JSON if the error cannot be encoded as XML,
+// such as the Data field is a map.
+func (c *Context) ErrorNegotiated(err error) {
+	switch {
+	case err == nil:
+		c.WriteHeader(200)
+	case c.acceptXML():
+		responderror(parseResponseCode(c.responseCode()), err, c.xmlOrJSON)
+	default:
+		c.Respond(result.Err(err))
+	}
+}
+
+func (c *Context) acceptXML() bool {
+	for _, ct := range c.Accept() {
+		switch ct {
+		case header.MIMEApplicationXML, header.MIMETextXML:
+			return true
+
+		case header.MIMEApplicationJSON, "":
+			return false
+		}
+	}
+	return false
+}
+
+func (c *Context) xmlOrJSON(code int, v any) {
+	err := c.WriteXML(code, v)
+	if err != nil && !c.ResponseWriter.WroteHeader() {
+		c.JSON(code, v)
+	} else {
+		c.AppendError(err)
+	}
+}
+
 // Blob sends a blob response with the status code and the content type.
+//
+// For the HEAD request, only set Content-Length and skip writing the body.
 func (c *Context) Blob(code int, contentType string, data []byte) {
 	c.SetContentType(contentType)
+	if c.isHead() {
+		c.writeHeadHeader(code, len(data))
+		return
+	}
+
 	c.WriteHeader(code)
 	if len(data) > 0 {
 		_, err := c.Write(data)
@@ -499,8 +739,15 @@ func (c *Context) Blob(code int, contentType string, data []byte) {
 }
 
 // BlobText sends a string blob response with the status code and the content type.
+//
+// For the HEAD request, only set Content-Length and skip writing the body.
 func (c *Context) BlobText(code int, contentType string, text string) {
 	c.SetContentType(contentType)
+	if c.isHead() {
+		c.writeHeadHeader(code, len(text))
+		return
+	}
+
 	c.WriteHeader(code)
 
 	if len(text) > 0 {
@@ -520,27 +767,226 @@ func (c *Context) HTML(code int, text string) {
 }
 
 // JSON sends a JSON response with the status code.
+//
+// For the HEAD request, only set Content-Length and skip writing the body.
 func (c *Context) JSON(code int, v any) {
-	c.AppendError(handler.JSON(c.ResponseWriter, code, v))
+	c.AppendError(c.WriteJSON(code, v))
 }
 
 // XML sends a XML response with the status code.
+//
+// For the HEAD request, only set Content-Length and skip writing the body.
 func (c *Context) XML(code int, v any) {
-	c.AppendError(handler.XML(c.ResponseWriter, code, v))
+	c.AppendError(c.WriteXML(code, v))
+}
+
+// WriteJSON is the same as JSON, but returns the error instead of appending it.
+func (c *Context) WriteJSON(code int, v any) error {
+	return c.writeBody(code, v, handler.JSON)
+}
+
+// WriteXML is the same as XML, but returns the error instead of appending it.
+func (c *Context) WriteXML(code int, v any) error {
+	return c.writeBody(code, v, handler.XML)
+}
+
+// MsgPack sends a msgpack response with the status code,
+// which uses handler.MsgPackEncoder to encode v.
+//
+// For the HEAD request, only set Content-Length and skip writing the body.
+func (c *Context) MsgPack(code int, v any) {
+	c.AppendError(c.writeBody(code, v, handler.MsgPack))
+}
+
+// YAML sends a yaml response with the status code,
+// which uses handler.YAMLEncoder to encode v.
+//
+// For the HEAD request, only set Content-Length and skip writing the body.
+func (c *Context) YAML(code int, v any) {
+	c.AppendError(c.writeBody(code, v, handler.YAML))
 }
 
 // Stream sends a streaming response with the status code and the content type.
 //
 // If contentType is empty, Content-Type is ignored.
+// For the HEAD request, skip reading r and writing the body.
 func (c *Context) Stream(code int, contentType string, r io.Reader) {
 	c.SetContentType(contentType)
 	c.WriteHeader(code)
+	if c.isHead() {
+		return
+	}
+
 	buf := getbytes()
 	_, err := io.CopyBuffer(c.ResponseWriter, r, buf.Buffer)
 	putbytes(buf)
 	c.AppendError(err)
 }
 
+// StreamLimit is the same as Stream, but copies at most maxBytes from r
+// and appends an error if r has more data, which also stops copying
+// and appends the context error when the request context is cancelled,
+// such as the client disconnects.
+//
+// If r has implemented io.Closer, it will be closed when the request
+// context is cancelled, so that the blocked reading returns early.
+//
+// If maxBytes is equal to or less than 0, there is no limit.
+func (c *Context) StreamLimit(code int, contentType string, r io.Reader, maxBytes int64) {
+	c.SetContentType(contentType)
+	c.WriteHeader(code)
+	if c.isHead() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if closer, ok := r.(io.Closer); ok {
+		stop := context.AfterFunc(ctx, func() { _ = closer.Close() })
+		defer stop()
+	}
+
+	r = ctxReader{ctx: ctx, r: r}
+	lr := r
+	if maxBytes > 0 {
+		lr = io.LimitReader(r, maxBytes)
+	}
+
+	buf := getbytes()
+	n, err := io.CopyBuffer(c.ResponseWriter, lr, buf.Buffer)
+	if err == nil && maxBytes > 0 && n == maxBytes {
+		err = checkNoMoreData(r, buf.Buffer[:1], maxBytes)
+	}
+	putbytes(buf)
+	c.AppendError(err)
+}
+
+// checkNoMoreData returns an error if r has more data,
+// which retries the empty reads like bufio.Reader.
+func checkNoMoreData(r io.Reader, p []byte, maxBytes int64) error {
+	for i := 0; i < 100; i++ {
+		switch n, err := r.Read(p); {
+		case n > 0:
+			return fmt.Errorf("the stream exceeds %d bytes", maxBytes)
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return err
+		}
+	}
+	return io.ErrNoProgress
+}
+
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (n int, err error) {
+	if err = r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if n, err = r.r.Read(p); err != nil && err != io.EOF {
+		if cerr := r.ctx.Err(); cerr != nil {
+			err = cerr
+		}
+	}
+	return
+}
+
+// JSONStream sends a JSON array response with the status code,
+// which encodes and writes the items one by one to keep memory flat
+// instead of marshaling the whole array, and flushes the response
+// every 100 items.
+//
+// If failing to encode an item, append the error and stop the iteration.
+// For the HEAD request, skip iterating the items and writing the body.
+func (c *Context) JSONStream(code int, items func(yield func(any) bool)) {
+	c.SetContentType(header.MIMEApplicationJSONCharsetUTF8)
+	c.WriteHeader(code)
+	if c.isHead() {
+		return
+	}
+
+	if _, err := io.WriteString(c.ResponseWriter, "["); err != nil {
+		c.AppendError(err)
+		return
+	}
+
+	var count int
+	enc := json.NewEncoder(c.ResponseWriter)
+	rc := http.NewResponseController(c.ResponseWriter)
+	items(func(item any) bool {
+		if count > 0 {
+			if _, err := io.WriteString(c.ResponseWriter, ","); err != nil {
+				c.AppendError(err)
+				return false
+			}
+		}
+
+		if err := enc.Encode(item); err != nil {
+			c.AppendError(err)
+			return false
+		}
+
+		if count++; count%100 == 0 {
+			_ = rc.Flush()
+		}
+		return true
+	})
+
+	_, err := io.WriteString(c.ResponseWriter, "]")
+	c.AppendError(err)
+}
+
+func (c *Context) isHead() bool {
+	return c.Request != nil && c.Request.Method == http.MethodHead
+}
+
+func (c *Context) writeHeadHeader(code, length int) {
+	if length > 0 {
+		c.Header().Set(header.HeaderContentLength, strconv.Itoa(length))
+	}
+	c.WriteHeader(code)
+}
+
+// writeBody writes the response body by write.
+//
+// For the HEAD request, the body is discarded, and the status code
+// is deferred to be written with Content-Length after write returns.
+func (c *Context) writeBody(code int, v any,
+	write func(http.ResponseWriter, int, any) error) (err error) {
+	if !c.isHead() {
+		return write(c.ResponseWriter, code, v)
+	}
+
+	w := &headResponseWriter{ResponseWriter: c.ResponseWriter}
+	if err = write(w, code, v); err == nil {
+		if w.code == 0 {
+			w.code = code
+		}
+		c.writeHeadHeader(w.code, w.size)
+	}
+	return
+}
+
+type headResponseWriter struct {
+	http.ResponseWriter
+	code int
+	size int
+}
+
+func (w *headResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *headResponseWriter) Write(p []byte) (int, error) {
+	w.size += len(p)
+	return len(p), nil
+}
+
 // Attachment sends a file as attachment.
 //
 // If filename is "", it will use the base name of the filepath instead.
@@ -628,7 +1074,12 @@ func defaultRespond(w http.ResponseWriter, r *http.Request, response result.Resp
 }
 
 func defaultContextRespond(c *Context, response result.Response) {
-	var xcode string
+	DefaultContextRespondByCode(c, c.responseCode(), response)
+}
+
+// responseCode returns the expected response code from the request header
+// or query "X-Response-Code".
+func (c *Context) responseCode() (xcode string) {
 	if c.Request != nil {
 		const XResponseCode = "X-Response-Code"
 		xcode = c.Request.Header.Get(XResponseCode)
@@ -636,7 +1087,7 @@ func defaultContextRespond(c *Context, response result.Response) {
 			xcode = c.GetQuery(XResponseCode)
 		}
 	}
-	DefaultContextRespondByCode(c, xcode, response)
+	return
 }
 
 func defaultContextRespondByCode(c *Context, xcode string, response result.Response) {
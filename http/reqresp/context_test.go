@@ -16,11 +16,21 @@ package reqresp
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/xgfone/go-apiserver/http/header"
+	"github.com/xgfone/go-apiserver/result"
+	"github.com/xgfone/go-apiserver/result/codeint"
+	"github.com/xgfone/go-binder"
 )
 
 func TestContextBinder(t *testing.T) {
@@ -74,3 +84,532 @@ func TestContextGetQueryInt64(t *testing.T) {
 		t.Errorf("expect error '%s', but got '%s'", "missing abc", s)
 	}
 }
+
+func TestContextReadBody(t *testing.T) {
+	c := AcquireContext()
+	c.Request, _ = http.NewRequest("POST", "http://localhost", bytes.NewBufferString("abc"))
+	if data, err := c.ReadBody(3); err != nil {
+		t.Error(err)
+	} else if s := string(data); s != "abc" {
+		t.Errorf("expect body '%s', but got '%s'", "abc", s)
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", bytes.NewBufferString("abcd"))
+	if _, err := c.ReadBody(3); err == nil {
+		t.Errorf("expect an error, but got nil")
+	} else if e, ok := err.(codeint.Error); !ok {
+		t.Errorf("expect a codeint.Error, but got %T", err)
+	} else if code := e.StatusCode(); code != 400 {
+		t.Errorf("expect status code %d, but got %d", 400, code)
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", io.MultiReader(bytes.NewBufferString("abcd")))
+	if _, err := c.ReadBody(3); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+}
+
+func TestContextErrorNegotiated(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	tests := []struct {
+		accept string
+		ctype  string
+		body   string
+	}{
+		{accept: "", ctype: header.MIMEApplicationJSONCharsetUTF8, body: `{"Code":404,"Message":"test"}` + "\n"},
+		{accept: "*/*", ctype: header.MIMEApplicationJSONCharsetUTF8, body: `{"Code":404,"Message":"test"}` + "\n"},
+		{accept: "application/json", ctype: header.MIMEApplicationJSONCharsetUTF8, body: `{"Code":404,"Message":"test"}` + "\n"},
+		{
+			accept: "application/json;q=0.8, application/xml",
+			ctype:  header.MIMEApplicationXMLCharsetUTF8,
+			body:   `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<Error><Code>404</Code><Message>test</Message></Error>`,
+		},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		c.ResponseWriter = AcquireResponseWriter(rec)
+		c.Request, _ = http.NewRequest("GET", "http://localhost", nil)
+		c.Request.Header.Set(header.HeaderAccept, test.accept)
+
+		c.ErrorNegotiated(codeint.ErrNotFound.WithMessage("test"))
+		if rec.Code != 404 {
+			t.Errorf("expect status code %d, but got %d", 404, rec.Code)
+		}
+		if ct := rec.Header().Get(header.HeaderContentType); ct != test.ctype {
+			t.Errorf("expect Content-Type '%s', but got '%s'", test.ctype, ct)
+		}
+		if body := rec.Body.String(); body != test.body {
+			t.Errorf("expect body '%s', but got '%s'", test.body, body)
+		}
+	}
+	// Fall back to JSON if the data cannot be encoded as XML.
+	rec := httptest.NewRecorder()
+	c.ResponseWriter = AcquireResponseWriter(rec)
+	c.Request, _ = http.NewRequest("GET", "http://localhost", nil)
+	c.Request.Header.Set(header.HeaderAccept, header.MIMEApplicationXML)
+	c.ErrorNegotiated(codeint.ErrBadRequest.WithData(map[string]any{"a": 1}))
+	if rec.Code != 400 {
+		t.Errorf("expect status code %d, but got %d", 400, rec.Code)
+	} else if ct := rec.Header().Get(header.HeaderContentType); ct != header.MIMEApplicationJSONCharsetUTF8 {
+		t.Errorf("expect Content-Type '%s', but got '%s'", header.MIMEApplicationJSONCharsetUTF8, ct)
+	} else if body := rec.Body.String(); body != `{"Data":{"a":1},"Code":400}`+"\n" {
+		t.Errorf("unexpected body '%s'", body)
+	}
+
+	// Use the context responder for JSON.
+	var responded bool
+	c.Responder = func(c *Context, r result.Response) { responded = true; DefaultContextRespond(c, r) }
+	defer func() { c.Responder = nil }()
+
+	c.ResponseWriter = AcquireResponseWriter(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "http://localhost", nil)
+	c.ErrorNegotiated(codeint.ErrNotFound)
+	if !responded {
+		t.Errorf("expect the context responder to be called")
+	}
+}
+
+func TestContextSetCacheFor(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	rec := httptest.NewRecorder()
+	c.ResponseWriter = AcquireResponseWriter(rec)
+
+	now := time.Now()
+	c.SetCacheFor(time.Hour)
+	if cc := rec.Header().Get(header.HeaderCacheControl); cc != "public, max-age=3600" {
+		t.Errorf("expect Cache-Control '%s', but got '%s'", "public, max-age=3600", cc)
+	}
+
+	expires, err := http.ParseTime(rec.Header().Get(header.HeaderExpires))
+	if err != nil {
+		t.Fatal(err)
+	} else if d := expires.Sub(now); d < time.Hour-time.Second || d > time.Hour+time.Second {
+		t.Errorf("expect Expires after about %s, but got %s", time.Hour, d)
+	}
+}
+
+func TestContextHead(t *testing.T) {
+	type Value struct{ A int }
+	tests := []struct {
+		write  func(c *Context)
+		length string
+	}{
+		{write: func(c *Context) { c.Text(200, "abc") }, length: "3"},
+		{write: func(c *Context) { c.Blob(200, header.MIMEApplicationOctetStream, []byte("abcd")) }, length: "4"},
+		{write: func(c *Context) { c.JSON(200, map[string]int{"a": 1}) }, length: "8"},
+		{write: func(c *Context) { c.XML(200, Value{A: 1}) }, length: "62"},
+	}
+
+	for i, test := range tests {
+		c := AcquireContext()
+		rec := httptest.NewRecorder()
+		c.ResponseWriter = AcquireResponseWriter(rec)
+		c.Request = httptest.NewRequest(http.MethodHead, "/", nil)
+		test.write(c)
+		ReleaseContext(c)
+
+		if rec.Code != 200 {
+			t.Errorf("%d: expect status code %d, but got %d", i, 200, rec.Code)
+		}
+		if length := rec.Header().Get(header.HeaderContentLength); length != test.length {
+			t.Errorf("%d: expect Content-Length '%s', but got '%s'", i, test.length, length)
+		}
+		if rec.Header().Get(header.HeaderContentType) == "" {
+			t.Errorf("%d: expect Content-Type, but got nothing", i)
+		}
+		if rec.Body.Len() != 0 {
+			t.Errorf("%d: expect no body, but got '%s'", i, rec.Body.String())
+		}
+	}
+}
+
+func TestContextJSONStream(t *testing.T) {
+	tests := []struct {
+		items []any
+		body  string
+	}{
+		{items: nil, body: `[]`},
+		{items: []any{1}, body: "[1\n]"},
+		{items: []any{1, "a", map[string]int{"b": 2}}, body: "[1\n,\"a\"\n,{\"b\":2}\n]"},
+	}
+
+	for i, test := range tests {
+		c := AcquireContext()
+		rec := httptest.NewRecorder()
+		c.ResponseWriter = AcquireResponseWriter(rec)
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.JSONStream(200, func(yield func(any) bool) {
+			for _, item := range test.items {
+				if !yield(item) {
+					return
+				}
+			}
+		})
+
+		if c.Err != nil {
+			t.Errorf("%d: unexpected error: %v", i, c.Err)
+		}
+		ReleaseContext(c)
+
+		if body := rec.Body.String(); body != test.body {
+			t.Errorf("%d: expect body '%s', but got '%s'", i, test.body, body)
+		}
+
+		var v []any
+		if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+			t.Errorf("%d: %v", i, err)
+		} else if len(v) != len(test.items) {
+			t.Errorf("%d: expect %d items, but got %d", i, len(test.items), len(v))
+		}
+	}
+}
+
+func TestContextBindPath(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	c.Data["name"] = "data"
+	c.SetPathParam("id", "123")
+	c.SetPathParam("name", "abc")
+
+	var req struct {
+		Id    int    `path:"id"`
+		Name  string `path:"name"`
+		Other string `path:"other" default:"xyz"`
+	}
+
+	if err := c.BindPath(&req); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Id != 123 {
+		t.Errorf("expect id %d, but got %d", 123, req.Id)
+	}
+	if req.Name != "abc" {
+		t.Errorf("expect name '%s', but got '%s'", "abc", req.Name)
+	}
+	if req.Other != "xyz" {
+		t.Errorf("expect other '%s', but got '%s'", "xyz", req.Other)
+	}
+}
+
+func TestContextGetHeader(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("X-Page-Size", "20")
+	c.Request.Header.Set("X-Debug", "true")
+	c.Request.Header.Set("X-Invalid", "abc")
+
+	if v, err := c.GetHeaderInt64("X-Page-Size", true); err != nil {
+		t.Error(err)
+	} else if v != 20 {
+		t.Errorf("expect %d, but got %d", 20, v)
+	}
+
+	if v, err := c.GetHeaderBool("X-Debug", true); err != nil {
+		t.Error(err)
+	} else if !v {
+		t.Errorf("expect true, but got false")
+	}
+
+	if _, err := c.GetHeaderInt64("X-Invalid", false); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+	if _, err := c.GetHeaderBool("X-Invalid", false); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	if v, err := c.GetHeaderInt64("X-Missing", false); err != nil || v != 0 {
+		t.Errorf("expect (0, nil), but got (%d, %v)", v, err)
+	}
+	if _, err := c.GetHeaderBool("X-Missing", true); err == nil {
+		t.Errorf("expect an error, but got nil")
+	} else if s := err.Error(); s != "missing X-Missing" {
+		t.Errorf("expect error '%s', but got '%s'", "missing X-Missing", s)
+	}
+}
+
+func TestContextTimeLeft(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, ok := c.Deadline(); ok {
+		t.Errorf("expect no deadline")
+	} else if left := c.TimeLeft(); left != NoDeadline {
+		t.Errorf("expect NoDeadline, but got %s", left)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Minute)
+	defer cancel()
+
+	c.Request = c.Request.WithContext(ctx)
+	if _, ok := c.Deadline(); !ok {
+		t.Errorf("expect a deadline")
+	} else if left := c.TimeLeft(); left <= 0 || left > time.Minute {
+		t.Errorf("expect the time left in (0, 1m], but got %s", left)
+	}
+}
+
+func TestContextWriteJSON(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	rec := httptest.NewRecorder()
+	c.ResponseWriter = AcquireResponseWriter(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if err := c.WriteJSON(200, func() {}); err == nil {
+		t.Errorf("expect an error, but got nil")
+	} else if c.Err != nil {
+		t.Errorf("expect no context error, but got %v", c.Err)
+	}
+
+	if err := c.WriteXML(200, map[string]int{}); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	if err := c.WriteJSON(201, map[string]int{"a": 1}); err != nil {
+		t.Error(err)
+	} else if rec.Code != 201 {
+		t.Errorf("expect status code %d, but got %d", 201, rec.Code)
+	}
+}
+
+func TestContextResetKeepData(t *testing.T) {
+	decoder := binder.DecoderFunc(func(dst, src any) error { return nil })
+
+	c := NewContext(4)
+	c.BodyDecoder = decoder
+	c.Data["key"] = "value"
+	c.Reg1 = 1
+
+	c.ResetKeepData()
+	if c.BodyDecoder == nil {
+		t.Errorf("expect the body decoder, but got nil")
+	}
+	if c.Reg1 != nil {
+		t.Errorf("expect the register to be cleared, but got %v", c.Reg1)
+	}
+	if value := c.GetDataString("key"); value != "value" {
+		t.Errorf("expect data value '%s', but got '%s'", "value", value)
+	}
+
+	c.Reset()
+	if c.BodyDecoder == nil {
+		t.Errorf("expect the body decoder, but got nil")
+	}
+	if len(c.Data) != 0 {
+		t.Errorf("expect no data, but got %v", c.Data)
+	}
+}
+
+func TestContextStreamLimit(t *testing.T) {
+	tests := []struct {
+		data  string
+		max   int64
+		body  string
+		error bool
+	}{
+		{data: "abc", max: 0, body: "abc"},
+		{data: "abc", max: 3, body: "abc"},
+		{data: "abc", max: 5, body: "abc"},
+		{data: "abcdef", max: 3, body: "abc", error: true},
+	}
+
+	for i, test := range tests {
+		c := AcquireContext()
+		rec := httptest.NewRecorder()
+		c.ResponseWriter = AcquireResponseWriter(rec)
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.StreamLimit(200, "text/plain", strings.NewReader(test.data), test.max)
+
+		if body := rec.Body.String(); body != test.body {
+			t.Errorf("%d: expect body '%s', but got '%s'", i, test.body, body)
+		}
+		if test.error && c.Err == nil {
+			t.Errorf("%d: expect an error, but got nil", i)
+		} else if !test.error && c.Err != nil {
+			t.Errorf("%d: unexpected error: %v", i, c.Err)
+		}
+		ReleaseContext(c)
+	}
+
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec := httptest.NewRecorder()
+	c.ResponseWriter = AcquireResponseWriter(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	c.StreamLimit(200, "text/plain", strings.NewReader("abc"), 0)
+	if !errors.Is(c.Err, context.Canceled) {
+		t.Errorf("expect error '%v', but got '%v'", context.Canceled, c.Err)
+	} else if rec.Body.Len() != 0 {
+		t.Errorf("expect no body, but got '%s'", rec.Body.String())
+	}
+
+	// The blocked reading is interrupted by the cancellation.
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	c.Reset()
+	c.ResponseWriter = AcquireResponseWriter(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	c.StreamLimit(200, "text/plain", pr, 0)
+	if !errors.Is(c.Err, context.DeadlineExceeded) {
+		t.Errorf("expect error '%v', but got '%v'", context.DeadlineExceeded, c.Err)
+	}
+
+	// The empty read is not the end of the stream.
+	c.Reset()
+	rec = httptest.NewRecorder()
+	c.ResponseWriter = AcquireResponseWriter(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.StreamLimit(200, "text/plain", &emptyReadReader{data: []byte("abcdef")}, 3)
+	if c.Err == nil {
+		t.Errorf("expect an error, but got nil")
+	} else if body := rec.Body.String(); body != "abc" {
+		t.Errorf("expect body '%s', but got '%s'", "abc", body)
+	}
+}
+
+// emptyReadReader returns (0, nil) before each non-empty read.
+type emptyReadReader struct {
+	data  []byte
+	empty bool
+}
+
+func (r *emptyReadReader) Read(p []byte) (int, error) {
+	if r.empty = !r.empty; r.empty {
+		return 0, nil
+	} else if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestContextAppendCodeError(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	c.AppendCodeError(400, nil)
+	if errs := c.Errors(); len(errs) != 0 {
+		t.Errorf("expect no errors, but got %d", len(errs))
+	}
+
+	c.AppendCodeError(400, errors.New("invalid name"))
+	c.AppendCodeError(422, errors.New("invalid age"))
+	if c.Err != nil {
+		t.Errorf("expect no c.Err, but got %v", c.Err)
+	}
+
+	errs := c.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("expect %d errors, but got %d", 2, len(errs))
+	}
+	if errs[0].Code != 400 || errs[0].Message != "invalid name" {
+		t.Errorf("unexpected the first error: %+v", errs[0])
+	}
+	if errs[1].Code != 422 || errs[1].Message != "invalid age" {
+		t.Errorf("unexpected the second error: %+v", errs[1])
+	}
+
+	errs[0].Code = 500
+	if code := c.Errors()[0].Code; code != 400 {
+		t.Errorf("expect the collected errors unchanged, but got code %d", code)
+	}
+
+	rec := httptest.NewRecorder()
+	c.ResponseWriter = AcquireResponseWriter(rec)
+	defer ReleaseResponseWriter(c.ResponseWriter)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if !c.RespondErrors() {
+		t.Errorf("expect the errors to be responded")
+	}
+
+	if rec.Code != 400 {
+		t.Errorf("expect status code %d, but got %d", 400, rec.Code)
+	}
+	expect := `{"Data":[{"Code":400,"Message":"invalid name"},{"Code":422,"Message":"invalid age"}],"Code":400}`
+	if body := strings.TrimSpace(rec.Body.String()); body != expect {
+		t.Errorf("expect body '%s', but got '%s'", expect, body)
+	}
+
+	c.Reset()
+	if errs := c.Errors(); len(errs) != 0 {
+		t.Errorf("expect no errors after reset, but got %d", len(errs))
+	}
+
+	// The default responder is not affected by the collected errors.
+	rec = httptest.NewRecorder()
+	c.ResponseWriter = AcquireResponseWriter(rec)
+	defer ReleaseResponseWriter(c.ResponseWriter)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.AppendCodeError(400, errors.New("invalid name"))
+	c.Success("ok")
+	if rec.Code != 200 {
+		t.Errorf("expect status code %d, but got %d", 200, rec.Code)
+	}
+
+	c.Reset()
+	if c.RespondErrors() {
+		t.Errorf("unexpect the errors to be responded")
+	}
+}
+
+func TestContextGetPath(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	c.SetPathParam("name", "abc")
+	c.SetPathParam("ratio", "1.5")
+	c.SetPathParam("enabled", "true")
+
+	if v, ok := c.GetPathString("name"); !ok || v != "abc" {
+		t.Errorf("expect path '%s', but got '%s' (%v)", "abc", v, ok)
+	}
+	if v, ok := c.GetPathString("missing"); ok || v != "" {
+		t.Errorf("expect no path, but got '%s' (%v)", v, ok)
+	}
+
+	if v, err := c.GetPathFloat64("ratio"); err != nil {
+		t.Error(err)
+	} else if v != 1.5 {
+		t.Errorf("expect %v, but got %v", 1.5, v)
+	}
+	if _, err := c.GetPathFloat64("name"); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	if v, err := c.GetPathBool("enabled"); err != nil {
+		t.Error(err)
+	} else if !v {
+		t.Errorf("expect true, but got false")
+	}
+	if _, err := c.GetPathBool("name"); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expect a panic, but got nil")
+		}
+	}()
+	_, _ = c.GetPathBool("missing")
+}
@@ -0,0 +1,229 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reqresp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/xgfone/go-apiserver/http/header"
+	"github.com/xgfone/go-apiserver/result/codeint"
+	"github.com/xgfone/go-binder"
+	"github.com/xgfone/go-defaults"
+)
+
+// DecodeOption is used to configure the json decoder of Context.DecodeBody.
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	disallowUnknownFields bool
+	useNumber             bool
+	maxDepth              int
+	maxBytes              int64
+}
+
+// defaultMaxDepthBytes is the default maximum bytes of the request body
+// to be read into memory when checking the json depth.
+const defaultMaxDepthBytes = 32 << 20 // 32MB
+
+// DisallowUnknownFields returns a decode option to let the decoder
+// return an error when the body contains the unknown fields.
+func DisallowUnknownFields() DecodeOption {
+	return func(o *decodeOptions) { o.disallowUnknownFields = true }
+}
+
+// UseNumber returns a decode option to let the decoder unmarshal
+// a number into an any value as a json.Number instead of a float64.
+func UseNumber() DecodeOption {
+	return func(o *decodeOptions) { o.useNumber = true }
+}
+
+// MaxDepth returns a decode option to let the decoder return an error
+// when the nesting depth of the objects and arrays exceeds depth.
+//
+// Because the body is read into memory to check the depth,
+// it is limited to 32MB by default, which may be changed by MaxBytes.
+//
+// If depth is equal to or less than 0, there is no limit.
+func MaxDepth(depth int) DecodeOption {
+	return func(o *decodeOptions) { o.maxDepth = depth }
+}
+
+// MaxBytes returns a decode option to let the decoder return an error
+// when the request body exceeds max bytes.
+//
+// If max is equal to or less than 0, there is no limit
+// except the default limit of MaxDepth.
+func MaxBytes(max int64) DecodeOption {
+	return func(o *decodeOptions) { o.maxBytes = max }
+}
+
+// DecodeBody decodes the json request body into v with the options,
+// then validates it like BindBody.
+//
+// If the body is not a single valid json value, or violates the options,
+// return codeint.ErrBadRequest.
+//
+// Unlike BindBody, it ignores BodyDecoder and the request Content-Type.
+func (c *Context) DecodeBody(v any, opts ...DecodeOption) (err error) {
+	var o decodeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return decodeJSONBody(c.Request, v, o)
+}
+
+// StrictBodyDecoder is a body decoder to decode the json request body
+// with DisallowUnknownFields, which may be set to Context.BodyDecoder
+// to let BindBody reject the unknown fields with codeint.ErrBadRequest.
+//
+// For the request whose Content-Type is not "application/json",
+// use binder.BodyDecoder instead.
+var StrictBodyDecoder binder.Decoder = binder.DecoderFunc(func(dst, src any) error {
+	req, ok := src.(*http.Request)
+	if !ok || header.ContentType(req.Header) != header.MIMEApplicationJSON {
+		return binder.BodyDecoder.Decode(dst, src)
+	}
+
+	err := decodeJSONBody(req, dst, decodeOptions{disallowUnknownFields: true})
+	if err != nil {
+		if _, ok := err.(codeint.Error); !ok {
+			err = codeint.ErrBadRequest.WithError(err)
+		}
+	}
+	return err
+})
+
+func decodeJSONBody(req *http.Request, v any, o decodeOptions) (err error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return defaults.ValidateStruct(v)
+	}
+
+	max := o.maxBytes
+	if max <= 0 && o.maxDepth > 0 {
+		max = defaultMaxDepthBytes
+	}
+	if max > 0 && req.ContentLength > max {
+		return errBodyTooLarge(max)
+	}
+
+	var r io.Reader = req.Body
+	if max > 0 {
+		r = &limitReader{r: r, n: max, max: max}
+	}
+
+	if o.maxDepth > 0 {
+		var data []byte
+		if data, err = io.ReadAll(r); err != nil {
+			return
+		} else if depth := jsonDepth(data); depth > o.maxDepth {
+			return codeint.ErrBadRequest.WithMessagef("the json depth %d exceeds the maximum %d", depth, o.maxDepth)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	dec := json.NewDecoder(r)
+	if o.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if o.useNumber {
+		dec.UseNumber()
+	}
+
+	switch err = dec.Decode(v); err {
+	case nil:
+		if _, err = dec.Token(); err == io.EOF {
+			err = nil
+		} else if err == nil {
+			err = errors.New("unexpected data after the json value")
+		}
+
+	case io.EOF:
+		err = nil
+	}
+
+	if err != nil {
+		if _, ok := err.(codeint.Error); !ok {
+			err = codeint.ErrBadRequest.WithError(err)
+		}
+		return
+	}
+
+	return defaults.ValidateStruct(v)
+}
+
+// limitReader is the same as io.LimitReader, but returns the error
+// codeint.ErrBadRequest instead of io.EOF when exceeding the limit.
+type limitReader struct {
+	r   io.Reader
+	n   int64
+	max int64
+}
+
+func (r *limitReader) Read(p []byte) (n int, err error) {
+	if r.n <= 0 {
+		// Check whether there is more data.
+		var b [1]byte
+		if n, err = r.r.Read(b[:]); n > 0 {
+			return 0, errBodyTooLarge(r.max)
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > r.n {
+		p = p[:r.n]
+	}
+	n, err = r.r.Read(p)
+	r.n -= int64(n)
+	return
+}
+
+// jsonDepth returns the maximum nesting depth of the objects and arrays.
+func jsonDepth(data []byte) (max int) {
+	var depth int
+	var instr, escaped bool
+	for _, b := range data {
+		switch {
+		case escaped:
+			escaped = false
+
+		case instr:
+			switch b {
+			case '\\':
+				escaped = true
+			case '"':
+				instr = false
+			}
+
+		default:
+			switch b {
+			case '"':
+				instr = true
+
+			case '{', '[':
+				if depth++; depth > max {
+					max = depth
+				}
+
+			case '}', ']':
+				depth--
+			}
+		}
+	}
+	return
+}
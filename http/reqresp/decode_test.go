@@ -0,0 +1,133 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reqresp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xgfone/go-apiserver/result/codeint"
+)
+
+func TestContextDecodeBody(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"id":12345678901234567890}`))
+	var m map[string]any
+	if err := c.DecodeBody(&m, UseNumber()); err != nil {
+		t.Error(err)
+	} else if n, ok := m["id"].(json.Number); !ok {
+		t.Errorf("expect a json.Number, but got %T", m["id"])
+	} else if s := n.String(); s != "12345678901234567890" {
+		t.Errorf("expect number '%s', but got '%s'", "12345678901234567890", s)
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"name":"a","age":1}`))
+	if err := c.DecodeBody(&req); err != nil {
+		t.Error(err)
+	} else if req.Name != "a" {
+		t.Errorf("expect name '%s', but got '%s'", "a", req.Name)
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"name":"a","age":1}`))
+	if err := c.DecodeBody(&req, DisallowUnknownFields()); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"a":[{"b":"]]"}]}`))
+	if err := c.DecodeBody(&m, MaxDepth(3)); err != nil {
+		t.Error(err)
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"a":[{"b":[]}]}`))
+	if err := c.DecodeBody(&m, MaxDepth(3)); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	for _, body := range []string{`{"name":`, `{"name":"a"} {"name":"b"}`, `{"name":"a"}x`} {
+		c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(body))
+		if err := c.DecodeBody(&req); err == nil {
+			t.Errorf("%s: expect an error, but got nil", body)
+		} else if e, ok := err.(codeint.Error); !ok || e.Code != 400 {
+			t.Errorf("%s: expect a 400 codeint.Error, but got %v", body, err)
+		}
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"name":"a"}`+"\n"))
+	if err := c.DecodeBody(&req); err != nil {
+		t.Error(err)
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"name":"abcdefghijk"}`))
+	c.Request.ContentLength = -1
+	if err := c.DecodeBody(&req, MaxBytes(10), MaxDepth(3)); err == nil {
+		t.Errorf("expect an error, but got nil")
+	} else if e, ok := err.(codeint.Error); !ok || e.Code != 400 {
+		t.Errorf("expect a 400 codeint.Error, but got %v", err)
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"name":"abcdefghijk"}`))
+	c.Request.ContentLength = -1
+	if err := c.DecodeBody(&req, MaxBytes(10)); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"name":"a"}`))
+	if err := c.DecodeBody(&req, MaxBytes(12)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStrictBodyDecoder(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+	c.BodyDecoder = StrictBodyDecoder
+
+	var req struct {
+		Name string `json:"name"`
+		Age  int    `json:"age" validate:"min(1)"`
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"name":"a","age":1}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if err := c.BindBody(&req); err != nil {
+		t.Error(err)
+	} else if req.Name != "a" || req.Age != 1 {
+		t.Errorf("unexpected request: %+v", req)
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"name":"a","age":1,"nmae":"b"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if err := c.BindBody(&req); err == nil {
+		t.Errorf("expect an error, but got nil")
+	} else if e, ok := err.(codeint.Error); !ok {
+		t.Errorf("expect a codeint.Error, but got %T", err)
+	} else if e.Code != 400 {
+		t.Errorf("expect code %d, but got %d", 400, e.Code)
+	}
+
+	c.Request, _ = http.NewRequest("POST", "http://localhost", strings.NewReader(`{"name":"a","age":0}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if err := c.BindBody(&req); err == nil {
+		t.Errorf("expect a validation error, but got nil")
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reqresp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xgfone/go-defaults"
+)
+
+// Scalar is the scalar types supported by Query and Data.
+type Scalar interface {
+	bool | string | float32 | float64 |
+		int | int8 | int16 | int32 | int64 |
+		uint | uint8 | uint16 | uint32 | uint64 |
+		time.Duration | time.Time
+}
+
+// Query returns the query value by the key and converts it to T.
+//
+// If the key does not exist and required is false, return (ZERO, nil).
+func Query[T Scalar](c *Context, key string, required bool) (value T, err error) {
+	if vs, exist := c.GetQueries()[key]; exist {
+		switch len(vs) {
+		case 0:
+		case 1:
+			if value, err = convert[T](vs[0]); err != nil {
+				err = fmt.Errorf("invalid query '%s': %s", key, err)
+			}
+		default:
+			err = fmt.Errorf("too query values for %s", key)
+		}
+	} else if required {
+		err = fmt.Errorf("missing %s", key)
+	}
+	return
+}
+
+// Data returns the value by the key from the field Data and converts it to T.
+//
+// If the key does not exist or the value cannot be converted, return (ZERO, false).
+func Data[T Scalar](c *Context, key string) (value T, ok bool) {
+	v, exist := c.Data[key]
+	if !exist {
+		return
+	}
+
+	if value, ok = v.(T); ok {
+		return
+	}
+
+	value, err := convert[T](v)
+	return value, err == nil
+}
+
+func convert[T Scalar](src any) (value T, err error) {
+	switch p := any(&value).(type) {
+	case *bool:
+		*p, err = defaults.ToBool(src)
+	case *string:
+		*p, err = defaults.ToString(src)
+	case *float32:
+		var f float64
+		f, err = defaults.ToFloat64(src)
+		*p = float32(f)
+	case *float64:
+		*p, err = defaults.ToFloat64(src)
+	case *int:
+		*p, err = toInt[int](src)
+	case *int8:
+		*p, err = toInt[int8](src)
+	case *int16:
+		*p, err = toInt[int16](src)
+	case *int32:
+		*p, err = toInt[int32](src)
+	case *int64:
+		*p, err = defaults.ToInt64(src)
+	case *uint:
+		*p, err = toUint[uint](src)
+	case *uint8:
+		*p, err = toUint[uint8](src)
+	case *uint16:
+		*p, err = toUint[uint16](src)
+	case *uint32:
+		*p, err = toUint[uint32](src)
+	case *uint64:
+		*p, err = defaults.ToUint64(src)
+	case *time.Duration:
+		*p, err = defaults.ToDuration(src)
+	case *time.Time:
+		*p, err = defaults.ToTime(src)
+	}
+	return
+}
+
+func toInt[T int | int8 | int16 | int32](src any) (value T, err error) {
+	i, err := defaults.ToInt64(src)
+	if err == nil {
+		if value = T(i); int64(value) != i {
+			err = fmt.Errorf("%d overflows %T", i, value)
+		}
+	}
+	return
+}
+
+func toUint[T uint | uint8 | uint16 | uint32](src any) (value T, err error) {
+	i, err := defaults.ToUint64(src)
+	if err == nil {
+		if value = T(i); uint64(value) != i {
+			err = fmt.Errorf("%d overflows %T", i, value)
+		}
+	}
+	return
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reqresp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestQuery(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	c.Request = httptest.NewRequest(http.MethodGet, "/?i=123&b=true&f=1.5&d=1s&big=1000&s=abc&m=1&m=2", nil)
+
+	if v, err := Query[int](c, "i", true); err != nil || v != 123 {
+		t.Errorf("expect (%d, nil), but got (%d, %v)", 123, v, err)
+	}
+	if v, err := Query[bool](c, "b", true); err != nil || !v {
+		t.Errorf("expect (true, nil), but got (%v, %v)", v, err)
+	}
+	if v, err := Query[float64](c, "f", true); err != nil || v != 1.5 {
+		t.Errorf("expect (%v, nil), but got (%v, %v)", 1.5, v, err)
+	}
+	if v, err := Query[time.Duration](c, "d", true); err != nil || v != time.Second {
+		t.Errorf("expect (%s, nil), but got (%s, %v)", time.Second, v, err)
+	}
+	if v, err := Query[uint16](c, "missing", false); err != nil || v != 0 {
+		t.Errorf("expect (0, nil), but got (%d, %v)", v, err)
+	}
+
+	if _, err := Query[int](c, "missing", true); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+	if _, err := Query[int](c, "s", true); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+	if _, err := Query[int8](c, "big", true); err == nil {
+		t.Errorf("expect an overflow error, but got nil")
+	}
+	if _, err := Query[int](c, "m", true); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+}
+
+func TestData(t *testing.T) {
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	c.Data["int"] = 123
+	c.Data["str"] = "456"
+	c.Data["abc"] = "abc"
+
+	if v, ok := Data[int](c, "int"); !ok || v != 123 {
+		t.Errorf("expect (%d, true), but got (%d, %v)", 123, v, ok)
+	}
+	if v, ok := Data[int64](c, "str"); !ok || v != 456 {
+		t.Errorf("expect (%d, true), but got (%d, %v)", 456, v, ok)
+	}
+	if v, ok := Data[string](c, "int"); !ok || v != "123" {
+		t.Errorf("expect (%s, true), but got (%s, %v)", "123", v, ok)
+	}
+	if _, ok := Data[int](c, "abc"); ok {
+		t.Errorf("expect false, but got true")
+	}
+	if _, ok := Data[int](c, "missing"); ok {
+		t.Errorf("expect false, but got true")
+	}
+}
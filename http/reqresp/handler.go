@@ -69,64 +69,44 @@ func runhandler(w http.ResponseWriter, r *http.Request, f Handler) {
 //
 // For "std", it will guess the status code from the error.
 func RespondErrorWithContextByCode(c *Context, xcode string, err error) {
+	RespondErrorWithContextAndStatusCode(c, parseResponseCode(xcode), err)
+}
+
+func parseResponseCode(xcode string) int {
 	switch xcode {
 	case "", "std":
-		RespondErrorWithContextAndStatusCode(c, 0, err)
-
-	case "200":
-		RespondErrorWithContextAndStatusCode(c, 200, err)
-
-	case "400":
-		RespondErrorWithContextAndStatusCode(c, 400, err)
-
-	case "500":
-		RespondErrorWithContextAndStatusCode(c, 500, err)
+		return 0
 
 	default:
 		code, _ := strconv.ParseInt(xcode, 10, 16)
 		if code >= 600 || code < 200 {
 			code = 0
 		}
-
-		RespondErrorWithContextAndStatusCode(c, int(code), err)
+		return int(code)
 	}
 }
 
 // If statuscode is equal to 0, guess it from the error.
 func RespondErrorWithContextAndStatusCode(c *Context, statuscode int, err error) {
-	if statuscode == 0 {
-		responderrorstd(c, err)
-	} else {
-		responderror(c, statuscode, err)
-	}
+	responderror(statuscode, err, c.JSON)
 }
 
-func responderror(c *Context, statuscode int, err error) {
-	switch err.(type) {
-	case codeint.Error, json.Marshaler:
-	default:
-		err = codeint.ErrInternalServerError.WithError(err)
+func responderror(statuscode int, err error, write func(int, any)) {
+	if statuscode == 0 {
+		if e, ok := err.(StatusCoder); ok {
+			statuscode = e.StatusCode()
+		} else {
+			statuscode = 500
+		}
 	}
 
-	c.JSON(statuscode, err)
-}
-
-func responderrorstd(c *Context, err error) {
-	var statuscode int
-	switch e := err.(type) {
+	switch err.(type) {
 	case codeint.Error, json.Marshaler:
-		statuscode = getStatusCodeFromError(err)
-
-	case StatusCoder:
-		statuscode = e.StatusCode()
-		err = codeint.ErrInternalServerError.WithError(err)
-
 	default:
-		statuscode = getStatusCodeFromError(err)
 		err = codeint.ErrInternalServerError.WithError(err)
 	}
 
-	c.JSON(statuscode, err)
+	write(statuscode, err)
 }
 
 func getStatusCodeFromError(err error) int {
@@ -0,0 +1,88 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reqresp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/xgfone/go-apiserver/http/header"
+	"github.com/xgfone/go-apiserver/result"
+	"github.com/xgfone/go-apiserver/result/codeint"
+)
+
+// Problem is the problem details for HTTP APIs defined by RFC 7807.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   int    `json:"code,omitempty"`
+}
+
+// NewProblem builds a problem from the error.
+//
+// The status code is guessed from the error like the standard responder,
+// and the code and detail come from codeint.Error if err is or wraps it.
+// For other errors with the status code 5xx, the detail is generic
+// to avoid leaking the internal error message to the client.
+func NewProblem(err error) Problem {
+	status := getStatusCodeFromError(err)
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: err.Error(),
+	}
+	if status >= 500 {
+		problem.Detail = "the server failed to handle the request"
+	}
+
+	var e codeint.Error
+	if errors.As(err, &e) {
+		problem.Code = e.Code
+		problem.Detail = e.Message
+		if status == 500 {
+			problem.Status = e.StatusCode()
+			problem.Title = http.StatusText(problem.Status)
+		}
+	}
+
+	return problem
+}
+
+// ProblemJSONRespond is a result responder based on Context, which sends
+// the error as "application/problem+json" defined by RFC 7807.
+//
+// It can be used to replace DefaultContextRespond. For example,
+//
+//	reqresp.DefaultContextRespond = reqresp.ProblemJSONRespond
+func ProblemJSONRespond(c *Context, response result.Response) {
+	if response.Error == nil {
+		c.JSON(200, response.Data)
+		return
+	}
+
+	problem := NewProblem(response.Error)
+	data, err := json.Marshal(problem)
+	if err != nil {
+		c.AppendError(err)
+		c.WriteHeader(500)
+		return
+	}
+
+	c.Blob(problem.Status, header.MIMEApplicationProblemJSON, data)
+}
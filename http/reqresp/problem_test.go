@@ -0,0 +1,84 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reqresp
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xgfone/go-apiserver/http/header"
+	"github.com/xgfone/go-apiserver/result"
+	"github.com/xgfone/go-apiserver/result/codeint"
+)
+
+func TestProblemJSONRespond(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+		body string
+	}{
+		{
+			err:  codeint.ErrNotFound.WithMessage("no user"),
+			code: 404,
+			body: `{"type":"about:blank","title":"Not Found","status":404,"detail":"no user","code":404}`,
+		},
+		{
+			err:  fmt.Errorf("wrap: %w", codeint.ErrUnauthorized.WithMessage("invalid token")),
+			code: 401,
+			body: `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"invalid token","code":401}`,
+		},
+		{
+			err:  errors.New("test"),
+			code: 500,
+			body: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"the server failed to handle the request"}`,
+		},
+		{
+			err:  statusError{code: 409, msg: "conflict"},
+			code: 409,
+			body: `{"type":"about:blank","title":"Conflict","status":409,"detail":"conflict"}`,
+		},
+		{
+			err:  codeint.ErrInternalServerError.WithMessage("db error"),
+			code: 500,
+			body: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"db error","code":500}`,
+		},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		c := &Context{ResponseWriter: AcquireResponseWriter(rec)}
+		ProblemJSONRespond(c, result.Err(test.err))
+
+		if rec.Code != test.code {
+			t.Errorf("expect status code %d, but got %d", test.code, rec.Code)
+		}
+		if ct := rec.Header().Get(header.HeaderContentType); ct != header.MIMEApplicationProblemJSON {
+			t.Errorf("expect Content-Type '%s', but got '%s'", header.MIMEApplicationProblemJSON, ct)
+		}
+		if body := rec.Body.String(); body != test.body {
+			t.Errorf("expect body '%s', but got '%s'", test.body, body)
+		}
+	}
+}
+
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e statusError) Error() string   { return e.msg }
+func (e statusError) StatusCode() int { return e.code }
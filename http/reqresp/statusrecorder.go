@@ -0,0 +1,114 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reqresp
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"sync"
+)
+
+var _ ResponseWriter = new(StatusRecorder)
+
+// StatusRecorder is a ResponseWriter to record the status code
+// and the number of the written body bytes, which may be used by
+// the middlewares, such as access log and metrics.
+//
+// It forwards Flush, Hijack and Push to the wrapped http.ResponseWriter
+// if it supports them.
+type StatusRecorder struct {
+	http.ResponseWriter
+	code int
+	size int64
+}
+
+var srpool = &sync.Pool{New: func() any { return new(StatusRecorder) }}
+
+// AcquireStatusRecorder acquires a StatusRecorder with w from the pool.
+func AcquireStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	r := srpool.Get().(*StatusRecorder)
+	r.Reset(w)
+	return r
+}
+
+// ReleaseStatusRecorder releases the StatusRecorder into the pool.
+func ReleaseStatusRecorder(r *StatusRecorder) {
+	r.Reset(nil)
+	srpool.Put(r)
+}
+
+// Reset resets the status recorder with w.
+func (r *StatusRecorder) Reset(w http.ResponseWriter) {
+	*r = StatusRecorder{ResponseWriter: w}
+}
+
+// Unwrap returns the wrapped http.ResponseWriter.
+func (r *StatusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
+
+// WroteHeader reports whether the response header has been written.
+func (r *StatusRecorder) WroteHeader() bool { return r.code > 0 }
+
+// StatusCode returns the written status code.
+//
+// If the status code has not been written, return 200.
+func (r *StatusRecorder) StatusCode() int {
+	if r.code == 0 {
+		return 200
+	}
+	return r.code
+}
+
+// Size returns the number of the written body bytes.
+func (r *StatusRecorder) Size() int64 { return r.size }
+
+// WriteHeader implements the interface http.ResponseWriter.
+func (r *StatusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+		r.ResponseWriter.WriteHeader(code)
+	}
+}
+
+// Write implements the interface http.ResponseWriter.
+func (r *StatusRecorder) Write(p []byte) (n int, err error) {
+	if r.code == 0 {
+		r.code = 200
+	}
+	n, err = r.ResponseWriter.Write(p)
+	r.size += int64(n)
+	return
+}
+
+// Flush implements the interface http.Flusher.
+func (r *StatusRecorder) Flush() {
+	if r.code == 0 {
+		r.code = 200
+	}
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Hijack implements the interface http.Hijacker.
+func (r *StatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
+
+// Push implements the interface http.Pusher.
+func (r *StatusRecorder) Push(target string, opts *http.PushOptions) error {
+	if p, ok := r.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
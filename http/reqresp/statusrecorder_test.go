@@ -0,0 +1,61 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reqresp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusRecorder(t *testing.T) {
+	rec := httptest.NewRecorder()
+	r := AcquireStatusRecorder(rec)
+	defer ReleaseStatusRecorder(r)
+
+	if r.WroteHeader() {
+		t.Errorf("expect not to write the header")
+	} else if code := r.StatusCode(); code != 200 {
+		t.Errorf("expect status code %d, but got %d", 200, code)
+	}
+
+	r.WriteHeader(201)
+	r.WriteHeader(500)
+	_, _ = r.Write([]byte("abc"))
+	_, _ = r.Write([]byte("de"))
+	r.Flush()
+
+	if code := r.StatusCode(); code != 201 {
+		t.Errorf("expect status code %d, but got %d", 201, code)
+	} else if rec.Code != 201 {
+		t.Errorf("expect status code %d, but got %d", 201, rec.Code)
+	}
+	if size := r.Size(); size != 5 {
+		t.Errorf("expect size %d, but got %d", 5, size)
+	}
+	if !rec.Flushed {
+		t.Errorf("expect to flush the response")
+	}
+	if !WroteHeader(r) {
+		t.Errorf("expect to write the header")
+	}
+
+	if _, _, err := r.Hijack(); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+	if err := r.Push("/path", nil); err != http.ErrNotSupported {
+		t.Errorf("expect error '%v', but got '%v'", http.ErrNotSupported, err)
+	}
+}
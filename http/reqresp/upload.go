@@ -0,0 +1,120 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reqresp
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"os"
+
+	"github.com/xgfone/go-apiserver/http/header"
+	"github.com/xgfone/go-apiserver/result/codeint"
+)
+
+var (
+	// MaxMultipartMemory is the maximum memory to parse the multipart form,
+	// and the rest of the files are stored on the disk temporarily.
+	//
+	// Default: 32MB
+	MaxMultipartMemory int64 = 32 << 20
+
+	// MaxUploadedFileSize is the maximum size of the file saved by
+	// SaveUploadedFile. If equal to or less than 0, there is no limit.
+	//
+	// Default: 32MB
+	MaxUploadedFileSize int64 = 32 << 20
+)
+
+var errNotMultipart = codeint.ErrBadRequest.WithMessage("the request content type is not multipart/form-data")
+
+// UploadedFiles parses the multipart form and returns the uploaded files
+// of the form field.
+//
+// If the request content type is not multipart/form-data,
+// return codeint.ErrBadRequest.
+func (c *Context) UploadedFiles(formField string) ([]*multipart.FileHeader, error) {
+	if c.ContentType() != header.MIMEMultipartForm {
+		return nil, errNotMultipart
+	}
+
+	if c.Request.MultipartForm == nil {
+		if err := c.Request.ParseMultipartForm(MaxMultipartMemory); err != nil {
+			return nil, codeint.ErrBadRequest.WithError(err)
+		}
+	}
+
+	return c.Request.MultipartForm.File[formField], nil
+}
+
+// SaveUploadedFile saves the single uploaded file of the form field
+// into the file dstPath, and returns the number of the written bytes.
+//
+// If the file size exceeds MaxUploadedFileSize, return codeint.ErrBadRequest.
+// And if failing to save it, the partial file will be removed.
+func (c *Context) SaveUploadedFile(formField, dstPath string) (n int64, err error) {
+	files, err := c.UploadedFiles(formField)
+	if err != nil {
+		return
+	}
+
+	switch len(files) {
+	case 0:
+		return 0, codeint.ErrBadRequest.WithMessagef("missing the uploaded file '%s'", formField)
+	case 1:
+	default:
+		return 0, codeint.ErrBadRequest.WithMessagef("too many uploaded files for '%s'", formField)
+	}
+
+	fh := files[0]
+	if MaxUploadedFileSize > 0 && fh.Size > MaxUploadedFileSize {
+		return 0, errFileTooLarge(MaxUploadedFileSize)
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return
+	}
+
+	var r io.Reader = src
+	if MaxUploadedFileSize > 0 {
+		r = io.LimitReader(src, MaxUploadedFileSize+1)
+	}
+
+	n, err = io.Copy(dst, r)
+	if err == nil && MaxUploadedFileSize > 0 && n > MaxUploadedFileSize {
+		err = errFileTooLarge(MaxUploadedFileSize)
+	}
+
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		n = 0
+		err = errors.Join(err, os.Remove(dstPath))
+	}
+	return
+}
+
+func errFileTooLarge(max int64) error {
+	return codeint.ErrBadRequest.WithMessagef("the uploaded file exceeds %d bytes", max)
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reqresp
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newUploadRequest(t *testing.T, files map[string]string) *http.Request {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	for name, content := range files {
+		w, err := mw.CreateFormFile(strings.TrimRight(name, "0123456789"), name+".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestContextSaveUploadedFile(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "file.txt")
+
+	c := AcquireContext()
+	defer ReleaseContext(c)
+
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	c.Request.Header.Set("Content-Type", "application/json")
+	if _, err := c.SaveUploadedFile("file", dst); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	c.Request = newUploadRequest(t, map[string]string{"file": "abc", "files1": "1", "files2": "2"})
+	if n, err := c.SaveUploadedFile("file", dst); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Errorf("expect %d bytes, but got %d", 3, n)
+	} else if data, _ := os.ReadFile(dst); string(data) != "abc" {
+		t.Errorf("expect file content '%s', but got '%s'", "abc", data)
+	}
+
+	if files, err := c.UploadedFiles("files"); err != nil {
+		t.Error(err)
+	} else if len(files) != 2 {
+		t.Errorf("expect %d files, but got %d", 2, len(files))
+	}
+
+	if _, err := c.SaveUploadedFile("files", dst); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+	if _, err := c.SaveUploadedFile("missing", dst); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	defer func(max int64) { MaxUploadedFileSize = max }(MaxUploadedFileSize)
+	MaxUploadedFileSize = 2
+
+	dst = filepath.Join(dir, "large.txt")
+	if _, err := c.SaveUploadedFile("file", dst); err == nil {
+		t.Errorf("expect an error, but got nil")
+	} else if _, err = os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("expect the file not to exist, but got %v", err)
+	}
+}
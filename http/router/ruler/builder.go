@@ -18,8 +18,10 @@ import (
 	"net/http"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/xgfone/go-apiserver/http/middleware"
+	"github.com/xgfone/go-apiserver/http/middleware/timeout"
 	"github.com/xgfone/go-apiserver/http/reqresp"
 	matcher "github.com/xgfone/go-http-matcher"
 )
@@ -50,21 +52,29 @@ func (r *Router) Host(host string) RouteBuilder {
 
 // Route returns a new route builder.
 func (r *Router) RouteBuilder() RouteBuilder {
-	return NewRouteBuilder(r.Register)
+	b := NewRouteBuilder(r.Register)
+	b.router = r
+	return b
 }
 
 // RouteBuilder is used to build the route.
 type RouteBuilder struct {
 	register func(Route)
+	router   *Router // the router that the builder is created from, which may be nil
 
-	auth  middleware.Middleware
-	mdws  middleware.Middlewares
-	group string
-	route Route
+	auth    middleware.Middleware
+	timeout middleware.Middleware
+	mdws    middleware.Middlewares
+	group   string
+	route   Route
 
 	host   matcher.Matcher
 	path   matcher.Matcher
+	tmpl   string
+	prefix bool
+	slash  bool
 	method matcher.Matcher
+	mname  string
 	others []matcher.Matcher
 }
 
@@ -89,6 +99,36 @@ func (b RouteBuilder) Use(middlewares ...middleware.Middleware) RouteBuilder {
 	return b
 }
 
+// UsePriority appends the http handler middlewares with the priority,
+// which overrides their own priorities.
+//
+// All the middlewares of the route are sorted by the priority
+// before wrapping the handler: the smaller the value, the earlier
+// the middleware runs, such as -1 before 0 before 1. And the middlewares
+// without the priority, such as those appended by Use, have the priority 0
+// and keep the insertion order.
+func (b RouteBuilder) UsePriority(priority int, middlewares ...middleware.Middleware) RouteBuilder {
+	if len(middlewares) == 0 {
+		return b
+	}
+
+	mws := make(middleware.Middlewares, len(middlewares))
+	for i, mw := range middlewares {
+		mws[i] = priorityMiddleware{Middleware: mw, priority: priority}
+	}
+
+	b.mdws = b.mdws.Append(mws...)
+	return b
+}
+
+type priorityMiddleware struct {
+	middleware.Middleware
+	priority int
+}
+
+func (m priorityMiddleware) Name() string  { return middleware.GetName(m.Middleware) }
+func (m priorityMiddleware) Priority() int { return m.priority }
+
 // UseFunc appends the http handler function middlewares that act on the later handler.
 func (b RouteBuilder) UseFunc(middlewares ...middleware.MiddlewareFunc) RouteBuilder {
 	b.mdws = b.mdws.AppendFunc(middlewares...)
@@ -101,6 +141,23 @@ func (b RouteBuilder) Auth(auth middleware.Middleware) RouteBuilder {
 	return b
 }
 
+// Timeout resets the timeout of the route handler and returns a new route builder.
+//
+// The request context will be cancelled after d, and respond 504
+// if the handler overruns. It only sets the context deadline and does not
+// interrupt the handler, see timeout.Timeout. The timeout middleware runs after all the other
+// middlewares with the same priority 0, so they can see the 504 response.
+//
+// If d is equal to or less than 0, clear it.
+func (b RouteBuilder) Timeout(d time.Duration) RouteBuilder {
+	if d > 0 {
+		b.timeout = timeout.Timeout("timeout", 0, d)
+	} else {
+		b.timeout = nil
+	}
+	return b
+}
+
 // Clone clones itself and returns a new route builder.
 func (b RouteBuilder) Clone() RouteBuilder {
 	b.others = slices.Clone(b.others)
@@ -136,6 +193,13 @@ func (b RouteBuilder) Priority(priority int) RouteBuilder {
 	return b
 }
 
+// Name sets the name of the route, which is used to build the url
+// by the method Router.URL.
+func (b RouteBuilder) Name(name string) RouteBuilder {
+	b.route.Name = name
+	return b
+}
+
 // Desc sets the description of the route.
 func (b RouteBuilder) Desc(desc string) RouteBuilder {
 	b.route.Desc = desc
@@ -168,7 +232,10 @@ func (b RouteBuilder) Path(path string) RouteBuilder {
 		}
 	}
 
+	b.tmpl = fixPath(path)
 	b.path = newPathMatcher(path)
+	b.slash = hasTrailingSlash(path)
+	b.prefix = false
 	return b
 }
 
@@ -195,22 +262,49 @@ func (b RouteBuilder) PathPrefix(pathPrefix string) RouteBuilder {
 		}
 	}
 
+	b.tmpl = fixPath(pathPrefix)
 	b.path = newPathPrefixMatcher(pathPrefix)
+	b.slash = false
+	b.prefix = true
 	return b
 }
 
 // Method adds the method match ruler.
 func (b RouteBuilder) Method(method string) RouteBuilder {
 	b.method = matcher.Method(method)
+	b.mname = strings.ToUpper(method)
 	return b
 }
 
-// Host adds the host match ruler.
+// Host adds the host match ruler, see the function Host.
 func (b RouteBuilder) Host(host string) RouteBuilder {
-	b.host = matcher.Host(host)
+	b.host = Host(host)
 	return b
 }
 
+// ClientIPForwarded adds the client ip match ruler,
+// see the function ClientIPForwarded.
+//
+// If clientIP or trustedProxies is invalid, it will panic.
+func (b RouteBuilder) ClientIPForwarded(clientIP string, trustedProxies ...string) RouteBuilder {
+	m, err := ClientIPForwarded(clientIP, trustedProxies...)
+	if err != nil {
+		panic(err)
+	}
+	return b.Matchers(m)
+}
+
+// Schedule adds the schedule match ruler, see the function Schedule.
+//
+// If spec is invalid, it will panic.
+func (b RouteBuilder) Schedule(spec string) RouteBuilder {
+	m, err := Schedule(spec)
+	if err != nil {
+		panic(err)
+	}
+	return b.Matchers(m)
+}
+
 // Matchers adds other matchers.
 func (b RouteBuilder) Matchers(matchers ...matcher.Matcher) RouteBuilder {
 	b.others = append(b.others, matchers...)
@@ -233,6 +327,17 @@ func tryAppendMatcher(ms []matcher.Matcher, m matcher.Matcher) []matcher.Matcher
 	return ms
 }
 
+func newMatcherWithoutMethod(b RouteBuilder, cap int) matcher.Matcher {
+	matchers := make([]matcher.Matcher, 0, cap)
+	matchers = tryAppendMatcher(matchers, b.host)
+	matchers = tryAppendMatcher(matchers, b.path)
+	matchers = append(matchers, b.others...)
+	if len(matchers) == 0 {
+		return matcher.New(0, "", matcher.AlwaysTrue)
+	}
+	return matcher.And(matchers...)
+}
+
 func (b RouteBuilder) newRoute(handler http.Handler) (route Route) {
 	matchers := make([]matcher.Matcher, 0, 3+len(b.others))
 	matchers = tryAppendMatcher(matchers, b.host)
@@ -244,12 +349,22 @@ func (b RouteBuilder) newRoute(handler http.Handler) (route Route) {
 	route = b.route
 	route.Matcher = matcher
 	route.Handler = handler
+	route.path = b.tmpl
+	route.prefix = b.prefix
+	route.slash = b.slash
+	if b.method != nil {
+		route.method = b.mname
+		route.others = newMatcherWithoutMethod(b, len(matchers)-1)
+	}
 
-	mdws := make(middleware.Middlewares, 0, len(b.mdws)+1)
+	mdws := make(middleware.Middlewares, 0, len(b.mdws)+2)
 	mdws = append(mdws, b.mdws...)
 	if b.auth != nil {
 		mdws = append(mdws, b.auth)
 	}
+	if b.timeout != nil {
+		mdws = append(mdws, b.timeout)
+	}
 	if len(mdws) > 0 {
 		mdws.Sort()
 		route.Use(mdws...)
@@ -0,0 +1,72 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruler
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/xgfone/go-apiserver/http/reqresp"
+)
+
+// Mount is equal to r.RouteBuilder().Mount(pathPrefix, sub).
+func (r *Router) Mount(pathPrefix string, sub http.Handler) {
+	r.RouteBuilder().Mount(pathPrefix, sub)
+}
+
+// Mount registers a route with the path prefix pathPrefix, which strips
+// the matched prefix from the request path and delegates it to sub,
+// such as another ruler.Router.
+//
+// The middlewares of the builder and its router run before sub.
+//
+// If pathPrefix contains the path parameters, such as "/users/{id}",
+// they have been captured into the Data of reqresp.Context by the matcher
+// before delegating. So sub can get them by Context.GetDataString, etc.
+func (b RouteBuilder) Mount(pathPrefix string, sub http.Handler) RouteBuilder {
+	b = b.PathPrefix(pathPrefix)
+	return b.Handler(newMountHandler(b.tmpl, sub))
+}
+
+func newMountHandler(prefix string, sub http.Handler) http.Handler {
+	var p urlPath
+	if prefix != "/" {
+		p = newURLPath(prefix, true)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if p.rawPath != "" {
+			path, _ = p.trimPrefix(path)
+		}
+		if path == "" {
+			path = "/"
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = path
+		r2.URL.RawPath = ""
+
+		if c := reqresp.GetContext(r.Context()); c != nil {
+			c.Request = r2
+			defer func() { c.Request = r }()
+		}
+
+		sub.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xgfone/go-apiserver/http/reqresp"
+)
+
+func TestRouterMount(t *testing.T) {
+	sub := NewRouter()
+	sub.Path("/").GETContext(func(c *reqresp.Context) {
+		c.Text(200, "index:"+c.GetDataString("id"))
+	})
+	sub.Path("/posts/{pid}").GETContext(func(c *reqresp.Context) {
+		c.Text(200, c.GetDataString("id")+":"+c.GetDataString("pid")+":"+c.Request.URL.Path)
+	})
+
+	var runs int
+	r := NewRouter()
+	r.Group("/api").UseFunc(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			runs++
+			next.ServeHTTP(w, r)
+		})
+	}).Mount("/users/{id}", sub)
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{path: "/api/users/123", code: 200, body: "index:123"},
+		{path: "/api/users/123/posts/456", code: 200, body: "123:456:/posts/456"},
+		{path: "/api/users/123/none", code: 404},
+		{path: "/api/none", code: 404},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		c := reqresp.AcquireContext()
+		c.Request = req.WithContext(reqresp.SetContext(req.Context(), c))
+		c.ResponseWriter = reqresp.AcquireResponseWriter(rec)
+		r.ServeHTTP(c.ResponseWriter, c.Request)
+		reqresp.ReleaseResponseWriter(c.ResponseWriter)
+		reqresp.ReleaseContext(c)
+
+		if rec.Code != test.code {
+			t.Errorf("%s: expect status code %d, but got %d", test.path, test.code, rec.Code)
+		} else if test.body != "" && rec.Body.String() != test.body {
+			t.Errorf("%s: expect body '%s', but got '%s'", test.path, test.body, rec.Body.String())
+		}
+	}
+
+	if runs != 3 {
+		t.Errorf("expect the middleware to run %d times, but got %d", 3, runs)
+	}
+}
+
+func TestRouterMountPrefix(t *testing.T) {
+	sub := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	})
+
+	r := NewRouter()
+	r.Mount("/v1/{name}/items", sub)
+	r.Mount("/static/", sub)
+	r.Mount("/", sub)
+
+	tests := []struct {
+		path string
+		body string
+	}{
+		{path: "/v1/users/items", body: "/"},
+		{path: "/v1/users/items/", body: "/"},
+		{path: "/v1/users/items/123", body: "/123"},
+		{path: "/v1/users/list", body: "/v1/users/list"},
+		{path: "/static", body: "/"},
+		{path: "/static/", body: "/"},
+		{path: "/static/a/b", body: "/a/b"},
+		{path: "/other/a", body: "/other/a"},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if body := rec.Body.String(); body != test.body {
+			t.Errorf("%s: expect body '%s', but got '%s'", test.path, test.body, body)
+		}
+	}
+}
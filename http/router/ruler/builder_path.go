@@ -55,13 +55,8 @@ type urlPath struct {
 func (p urlPath) Match(r *http.Request) (ok bool) {
 	if p.plen == 0 {
 		if p.isPrefix {
-			path := matcher.GetPath(r)
-			if !strings.HasPrefix(path, p.rawPath) {
-				return false
-			}
-
-			mlen := len(p.rawPath)
-			return mlen == len(path) || path[mlen] == '/'
+			_, ok = p.trimPrefix(matcher.GetPath(r))
+			return
 		}
 
 		path := matcher.GetPath(r)
@@ -74,15 +69,33 @@ func (p urlPath) Match(r *http.Request) (ok bool) {
 	}
 
 	args := kvpool.Get().(*kvswrapper)
-	path := matcher.GetPath(r)
+	path, ok := p.walk(matcher.GetPath(r), args)
+	if ok && !p.isPrefix {
+		ok = len(path) == 0
+	}
+
+	if ok {
+		if c := reqresp.GetContext(r.Context()); c != nil {
+			for i, _len := 0, len(args.kvs); i < _len; i++ {
+				c.SetPathParam(args.kvs[i].key, args.kvs[i].value)
+			}
+		}
+	}
+
+	kvpool.Put(args.reset())
+	return
+}
 
+// walk walks through the path parameters from the start of path,
+// appends the captured arguments into args if not nil,
+// and returns the rest path.
+func (p urlPath) walk(path string, args *kvswrapper) (rest string, ok bool) {
 	var i int
 	for ; i < p.plen && len(path) > 0; i++ {
 		ap := p.paths[i]
 		if len(ap.name) == 0 {
 			if !strings.HasPrefix(path, ap.path) {
-				kvpool.Put(args.reset())
-				return false
+				return "", false
 			}
 
 			path = path[len(ap.path):]
@@ -90,32 +103,52 @@ func (p urlPath) Match(r *http.Request) (ok bool) {
 		}
 
 		if index := strings.IndexByte(path, '/'); index == -1 {
-			args.append(kv{key: ap.name, value: path})
+			if args != nil {
+				args.append(kv{key: ap.name, value: path})
+			}
 			path = ""
 		} else {
-			args.append(kv{key: ap.name, value: path[:index]})
+			if args != nil {
+				args.append(kv{key: ap.name, value: path[:index]})
+			}
 			path = path[index:]
 		}
 	}
+	return path, i == p.plen
+}
 
-	ok = i == p.plen
-	if ok && !p.isPrefix {
-		ok = len(path) == 0
+// trimPrefix trims the matched prefix from path and returns the rest.
+func (p urlPath) trimPrefix(path string) (rest string, ok bool) {
+	if p.plen > 0 {
+		return p.walk(path, nil)
 	}
 
-	if ok {
-		if c := reqresp.GetContext(r.Context()); c != nil {
-			for i, _len := 0, len(args.kvs); i < _len; i++ {
-				c.Data[args.kvs[i].key] = args.kvs[i].value
-			}
-		}
+	if rest, ok = strings.CutPrefix(path, p.rawPath); ok {
+		ok = rest == "" || rest[0] == '/'
 	}
-
-	kvpool.Put(args.reset())
 	return
 }
 
 func buildPathMatcher(desc, path string, isPrefix bool) matcher.Matcher {
+	p := newURLPath(path, isPrefix)
+
+	prio := matcher.PriorityPath
+	if isPrefix {
+		prio = matcher.PriorityPathPrefix
+	}
+
+	prefixlen := len(p.rawPath)
+	if len(p.paths) > 0 && p.paths[0].name == "" {
+		prefixlen = len(p.paths[0].path)
+	}
+	if prefixlen > 0 {
+		prio *= prefixlen
+	}
+
+	return matcher.New(prio, desc, p.Match)
+}
+
+func newURLPath(path string, isPrefix bool) urlPath {
 	p := urlPath{isPrefix: isPrefix, rawPath: path}
 
 	if strings.IndexByte(path, '{') > -1 && strings.IndexByte(path, '}') > -1 {
@@ -145,20 +178,7 @@ func buildPathMatcher(desc, path string, isPrefix bool) matcher.Matcher {
 		p.plen = len(p.paths)
 	}
 
-	prio := matcher.PriorityPath
-	if isPrefix {
-		prio = matcher.PriorityPathPrefix
-	}
-
-	prefixlen := len(p.rawPath)
-	if len(p.paths) > 0 && p.paths[0].name == "" {
-		prefixlen = len(p.paths[0].path)
-	}
-	if prefixlen > 0 {
-		prio *= prefixlen
-	}
-
-	return matcher.New(prio, desc, p.Match)
+	return p
 }
 
 func fixPath(path string) string {
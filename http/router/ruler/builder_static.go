@@ -0,0 +1,97 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruler
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xgfone/go-apiserver/http/header"
+	matcher "github.com/xgfone/go-http-matcher"
+)
+
+// Static registers a GET and HEAD route with the path prefix urlPrefix,
+// which serves the files under the directory dir after stripping urlPrefix
+// from the request path, and supports "If-Modified-Since", "Range", etc.
+// like http.FileServer.
+//
+// For the directory, serve its "index.html" if existing. Or, if listDir is
+// true, list the directory by http.FileServer; or, treat it as the missing.
+// For the missing file, use the NotFound handler of the router
+// that the builder is created from, or handler.Handler404.
+//
+// If maxAge is greater than 0, set the response header
+// "Cache-Control: public, max-age=N" for the served files.
+// Or, set "Cache-Control: no-cache" to let the client revalidate
+// by "Last-Modified" every time.
+func (b RouteBuilder) Static(urlPrefix, dir string, listDir bool, maxAge time.Duration) RouteBuilder {
+	b = b.PathPrefix(urlPrefix).Matchers(matcher.Method(http.MethodGet, http.MethodHead))
+	return b.Handler(newStaticHandler(b.router, b.tmpl, dir, listDir, maxAge))
+}
+
+func newStaticHandler(router *Router, prefix, dir string, listDir bool, maxAge time.Duration) http.Handler {
+	if prefix == "/" {
+		prefix = ""
+	}
+
+	cacheControl := "no-cache"
+	if maxAge > 0 {
+		cacheControl = "public, max-age=" + strconv.FormatInt(int64(maxAge/time.Second), 10)
+	}
+
+	root := http.Dir(dir)
+	files := http.StripPrefix(prefix, http.FileServer(root))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + strings.TrimPrefix(r.URL.Path, prefix))
+		f, err := root.Open(name)
+		if err != nil {
+			router.notFound(w, r)
+			return
+		}
+		defer f.Close()
+
+		fi, err := f.Stat()
+		if err != nil {
+			router.notFound(w, r)
+			return
+		}
+
+		if fi.IsDir() {
+			index, err := root.Open(path.Join(name, "index.html"))
+			if err != nil {
+				if listDir {
+					w.Header().Set(header.HeaderCacheControl, cacheControl)
+					files.ServeHTTP(w, r)
+				} else {
+					router.notFound(w, r)
+				}
+				return
+			}
+			defer index.Close()
+
+			f = index
+			if fi, err = f.Stat(); err != nil || fi.IsDir() {
+				router.notFound(w, r)
+				return
+			}
+		}
+
+		w.Header().Set(header.HeaderCacheControl, cacheControl)
+		http.ServeContent(w, r, fi.Name(), fi.ModTime(), f)
+	})
+}
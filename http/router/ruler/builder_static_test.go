@@ -0,0 +1,91 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRouteBuilderStatic(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("abc"), 0600); err != nil {
+		t.Fatal(err)
+	} else if err = os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "index"), 0700); err != nil {
+		t.Fatal(err)
+	} else if err = os.WriteFile(filepath.Join(dir, "index", "index.html"), []byte("index"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRouter()
+	r.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(410)
+	})
+	r.RouteBuilder().Static("/static", dir, false, time.Hour)
+	r.RouteBuilder().Static("/list", dir, true, 0)
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{path: "/static/file.txt", code: 200, body: "abc"},
+		{path: "/static/missing.txt", code: 410},
+		{path: "/static/sub/", code: 410},
+		{path: "/static/index/", code: 200, body: "index"},
+		{path: "/static/../file.txt", code: 200, body: "abc"},
+		{path: "/list/sub/", code: 200},
+		{path: "/list/missing.txt", code: 410},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if rec.Code != test.code {
+			t.Errorf("%s: expect status code %d, but got %d", test.path, test.code, rec.Code)
+		} else if test.body != "" && rec.Body.String() != test.body {
+			t.Errorf("%s: expect body '%s', but got '%s'", test.path, test.body, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/file.txt", nil))
+	if lm := rec.Header().Get("Last-Modified"); lm == "" {
+		t.Errorf("expect Last-Modified, but got nothing")
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=3600" {
+		t.Errorf("expect Cache-Control '%s', but got '%s'", "public, max-age=3600", cc)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/list/file.txt", nil))
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("expect Cache-Control '%s', but got '%s'", "no-cache", cc)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/missing.txt", nil))
+	if cc := rec.Header().Get("Cache-Control"); cc != "" {
+		t.Errorf("expect no Cache-Control, but got '%s'", cc)
+	}
+}
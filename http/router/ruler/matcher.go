@@ -0,0 +1,335 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/xgfone/go-apiserver/http/header"
+	"github.com/xgfone/go-apiserver/http/reqresp"
+	matcher "github.com/xgfone/go-http-matcher"
+	"github.com/xgfone/go-toolkit/netx"
+)
+
+// QueryCount returns a new matcher that checks whether the query key
+// appears at least min times, such as "?id=1&id=2&id=3".
+//
+// If a *reqresp.Context can be got from *http.Request,
+// use its cached queries instead of parsing the request query again.
+func QueryCount(key string, min int) (matcher.Matcher, error) {
+	if key == "" {
+		return nil, errors.New("QueryCount: the query key must not be empty")
+	} else if min < 1 {
+		return nil, fmt.Errorf("QueryCount: the minimum count must be greater than 0, but got %d", min)
+	}
+
+	desc := fmt.Sprintf("QueryCount(`%s`,%d)", key, min)
+	return matcher.New(matcher.PriorityQuery, desc, func(r *http.Request) bool {
+		return len(getQueries(r)[key]) >= min
+	}), nil
+}
+
+func getQueries(r *http.Request) url.Values {
+	if c := reqresp.GetContext(r.Context()); c != nil {
+		return c.GetQueries()
+	}
+	return r.URL.Query()
+}
+
+// Host returns a new matcher that checks whether the request host,
+// extracted by matcher.GetHost, is host, which supports the forms:
+//
+//	"www.example.com": match the exact host only.
+//	"*.example.com": match the single-label subdomain, such as "www.example.com",
+//	                 but not "example.com" or "a.b.example.com".
+//	".example.com": match the domain and all its subdomains, such as
+//	                "example.com", "www.example.com" and "a.b.example.com".
+//
+// The wildcard and suffix forms are matched by comparing the string suffix
+// instead of the regular expression. For others, use matcher.Host instead.
+func Host(host string) matcher.Matcher {
+	host = strings.ToLower(host)
+	desc := fmt.Sprintf("Host(`%s`)", host)
+
+	var match func(string) bool
+	switch {
+	case len(host) > 1 && host[0] == '.':
+		domain := host[1:]
+		match = func(s string) bool {
+			return s == domain || strings.HasSuffix(s, host)
+		}
+
+	case len(host) > 2 && strings.HasPrefix(host, "*."):
+		suffix := host[1:]
+		match = func(s string) bool {
+			label, ok := strings.CutSuffix(s, suffix)
+			return ok && label != "" && strings.IndexByte(label, '.') < 0
+		}
+
+	default:
+		return matcher.Host(host)
+	}
+
+	return matcher.New(matcher.PriorityHost*len(host), desc, func(r *http.Request) bool {
+		return match(matcher.GetHost(r))
+	})
+}
+
+// ClientIPForwarded returns a new matcher that checks whether the client ip
+// is clientIP, which may be an ip or a cidr, such as "1.2.3.4" or "1.2.3.0/24".
+//
+// Unlike matcher.ClientIP, if the peer, that's, the remote address,
+// is a trusted proxy, the client ip is extracted from the header
+// "X-Forwarded-For" from right to left by skipping the trusted proxies
+// and stopping at the invalid hop, or "X-Real-Ip" if "X-Forwarded-For"
+// does not exist. If trustedProxies is empty, trust the loopback
+// and private networks, such as "127.0.0.0/8", "10.0.0.0/8", etc.
+func ClientIPForwarded(clientIP string, trustedProxies ...string) (matcher.Matcher, error) {
+	client, err := parsePrefix(clientIP)
+	if err != nil {
+		return nil, fmt.Errorf("ClientIPForwarded: %w", err)
+	}
+
+	var proxies []netip.Prefix
+	if len(trustedProxies) == 0 {
+		trustedProxies = defaultTrustedProxies
+	}
+	for _, proxy := range trustedProxies {
+		prefix, err := parsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("ClientIPForwarded: %w", err)
+		}
+		proxies = append(proxies, prefix)
+	}
+
+	desc := fmt.Sprintf("ClientIPForwarded(`%s`)", clientIP)
+	return matcher.New(matcher.PriorityClientIP, desc, func(r *http.Request) bool {
+		return client.Contains(forwardedClientIP(r, proxies))
+	}), nil
+}
+
+var defaultTrustedProxies = []string{
+	"127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16",
+	"::1/128", "fc00::/7",
+}
+
+func parsePrefix(ip string) (netip.Prefix, error) {
+	if strings.IndexByte(ip, '/') > -1 {
+		return netip.ParsePrefix(ip)
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func isTrusted(proxies []netip.Prefix, addr netip.Addr) bool {
+	for _, proxy := range proxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseAddr(ip string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	return addr.Unmap(), err == nil
+}
+
+func forwardedClientIP(r *http.Request, proxies []netip.Prefix) netip.Addr {
+	host, _ := netx.SplitHostPort(r.RemoteAddr)
+	peer, ok := parseAddr(host)
+	if !ok || !isTrusted(proxies, peer) {
+		return peer
+	}
+
+	if values := r.Header.Values(header.HeaderXForwardedFor); len(values) > 0 {
+		// Stop at the invalid hop and use the last trusted proxy before it,
+		// because the hops on its left may be forged by the client.
+		client := peer
+		ips := strings.Split(strings.Join(values, ","), ",")
+		for i := len(ips) - 1; i >= 0; i-- {
+			addr, ok := parseAddr(ips[i])
+			if !ok {
+				break
+			} else if client = addr; !isTrusted(proxies, addr) {
+				break
+			}
+		}
+		return client
+	}
+
+	if addr, ok := parseAddr(r.Header.Get(header.HeaderXRealIP)); ok {
+		return addr
+	}
+
+	return peer
+}
+
+var timeNow = time.Now
+
+// Schedule returns a new matcher that checks whether the current time
+// is in the time window described by spec, whose format is
+//
+//	[DAYS] HH:MM-HH:MM [TIMEZONE]
+//
+// DAYS is a comma-separated list of the weekdays or the weekday ranges,
+// such as "Mon-Fri" or "Sat,Sun", and every day if missing.
+// The window contains the start and excludes the end. If the end is before
+// the start, such as "22:00-06:00", the window crosses the midnight
+// and belongs to the day that it starts on.
+// TIMEZONE is the IANA timezone name, such as "Asia/Shanghai",
+// and time.Local if missing.
+//
+// For example,
+//
+//	"09:00-17:00"
+//	"Mon-Fri 09:00-17:00"
+//	"Sat,Sun 10:00-12:00 UTC"
+//	"Fri 22:00-02:00 Asia/Shanghai"
+//
+// The priority of the matcher is matcher.PriorityQuery, which is the lowest
+// non-zero one, so that the route with the schedule is tried just before
+// the same route without it, that's, the latter is used as the fallback
+// out of the time window.
+func Schedule(spec string) (matcher.Matcher, error) {
+	s, err := parseSchedule(spec)
+	if err != nil {
+		return nil, fmt.Errorf("Schedule: %w", err)
+	}
+
+	desc := fmt.Sprintf("Schedule(`%s`)", spec)
+	return matcher.New(matcher.PriorityQuery, desc, func(*http.Request) bool {
+		return s.match(timeNow())
+	}), nil
+}
+
+type schedule struct {
+	loc   *time.Location
+	days  [7]bool
+	start int // minutes since the midnight
+	end   int // minutes since the midnight
+}
+
+func (s schedule) match(now time.Time) bool {
+	now = now.In(s.loc)
+	day := now.Weekday()
+	mins := now.Hour()*60 + now.Minute()
+
+	if s.start < s.end {
+		return s.days[day] && s.start <= mins && mins < s.end
+	}
+
+	// Cross the midnight.
+	prev := (day + 6) % 7
+	return (s.days[day] && mins >= s.start) || (s.days[prev] && mins < s.end)
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday,
+	"wed": time.Wednesday, "thu": time.Thursday, "fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func parseSchedule(spec string) (s schedule, err error) {
+	fields := strings.Fields(spec)
+	s.loc = time.Local
+
+	var window string
+	switch len(fields) {
+	case 1:
+		window = fields[0]
+		s.days = [7]bool{true, true, true, true, true, true, true}
+
+	case 2, 3:
+		if strings.IndexByte(fields[0], ':') > -1 {
+			if len(fields) == 3 {
+				return s, fmt.Errorf("invalid schedule '%s'", spec)
+			}
+			window = fields[0]
+			s.days = [7]bool{true, true, true, true, true, true, true}
+			s.loc, err = time.LoadLocation(fields[1])
+		} else {
+			window = fields[1]
+			s.days, err = parseWeekdays(fields[0])
+			if err == nil && len(fields) == 3 {
+				s.loc, err = time.LoadLocation(fields[2])
+			}
+		}
+		if err != nil {
+			return
+		}
+
+	default:
+		return s, fmt.Errorf("invalid schedule '%s'", spec)
+	}
+
+	start, end, ok := strings.Cut(window, "-")
+	if !ok {
+		return s, fmt.Errorf("invalid time window '%s'", window)
+	}
+	if s.start, err = parseClock(start); err != nil {
+		return
+	}
+	if s.end, err = parseClock(end); err != nil {
+		return
+	}
+	if s.start == s.end {
+		return s, fmt.Errorf("empty time window '%s'", window)
+	}
+	return
+}
+
+func parseWeekdays(s string) (days [7]bool, err error) {
+	for _, item := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(item, "-")
+		start, ok := weekdays[strings.ToLower(first)]
+		if !ok {
+			return days, fmt.Errorf("invalid weekday '%s'", first)
+		}
+
+		end := start
+		if isRange {
+			if end, ok = weekdays[strings.ToLower(last)]; !ok {
+				return days, fmt.Errorf("invalid weekday '%s'", last)
+			}
+		}
+
+		for day := start; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == end {
+				break
+			}
+		}
+	}
+	return
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time '%s'", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,268 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/xgfone/go-apiserver/http/handler"
+)
+
+func TestQueryCount(t *testing.T) {
+	if _, err := QueryCount("", 1); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+	if _, err := QueryCount("id", 0); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	m, err := QueryCount("id", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if desc := m.String(); desc != "QueryCount(`id`,2)" {
+		t.Errorf("expect desc '%s', but got '%s'", "QueryCount(`id`,2)", desc)
+	}
+
+	tests := []struct {
+		query string
+		match bool
+	}{
+		{query: "", match: false},
+		{query: "id=1", match: false},
+		{query: "id=1&id=2", match: true},
+		{query: "id=1&id=2&id=3", match: true},
+		{query: "id=1&ids=2", match: false},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/path?"+test.query, nil)
+		if match := m.Match(req); match != test.match {
+			t.Errorf("query '%s': expect match %v, but got %v", test.query, test.match, match)
+		}
+	}
+}
+
+func TestHost(t *testing.T) {
+	tests := []struct {
+		host  string
+		req   string
+		match bool
+	}{
+		{host: "www.example.com", req: "www.example.com", match: true},
+		{host: "www.example.com", req: "WWW.Example.com:80", match: true},
+		{host: "www.example.com", req: "api.example.com", match: false},
+
+		{host: "*.example.com", req: "www.example.com", match: true},
+		{host: "*.example.com", req: "a.b.example.com", match: false},
+		{host: "*.example.com", req: "example.com", match: false},
+		{host: "*.example.com", req: ".example.com", match: false},
+		{host: "*.example.com", req: "wwwexample.com", match: false},
+
+		{host: ".example.com", req: "example.com", match: true},
+		{host: ".example.com", req: "www.example.com", match: true},
+		{host: ".example.com", req: "a.b.example.com", match: true},
+		{host: ".example.com", req: "a.b.c.example.com:8080", match: true},
+		{host: ".example.com", req: "evilexample.com", match: false},
+		{host: ".example.com", req: "example.org", match: false},
+
+		{host: "*", req: "www.example.com", match: true},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = test.req
+		if match := Host(test.host).Match(req); match != test.match {
+			t.Errorf("host=%s, req=%s: expect match %v, but got %v",
+				test.host, test.req, test.match, match)
+		}
+	}
+
+	if desc := Host(".Example.com").String(); desc != "Host(`.example.com`)" {
+		t.Errorf("expect desc '%s', but got '%s'", "Host(`.example.com`)", desc)
+	}
+}
+
+func TestRouteBuilderHost(t *testing.T) {
+	router := NewRouter()
+	router.Host("www.example.com").Path("/path").GET(handler.Handler200)
+	router.Host("*.example.com").Path("/path").GET(handler.Handler400)
+	router.Host(".example.com").Path("/path").GET(handler.Handler204)
+
+	for host, code := range map[string]int{
+		"www.example.com":   200,
+		"api.example.com":   400,
+		"example.com":       204,
+		"a.b.example.com":   204,
+		"a.b.c.example.com": 204,
+		"example.org":       404,
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/path", nil)
+		req.Host = host
+		router.ServeHTTP(rec, req)
+		if rec.Code != code {
+			t.Errorf("%s: expect status code %d, but got %d", host, code, rec.Code)
+		}
+	}
+}
+
+func TestClientIPForwarded(t *testing.T) {
+	if _, err := ClientIPForwarded("invalid"); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+	if _, err := ClientIPForwarded("1.2.3.4", "invalid"); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+
+	m, err := ClientIPForwarded("1.2.3.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		remote string
+		xff    string
+		xrip   string
+		match  bool
+	}{
+		{remote: "1.2.3.4:80", match: true},
+		{remote: "5.6.7.8:80", match: false},
+		{remote: "5.6.7.8:80", xff: "1.2.3.4", match: false}, // untrusted peer
+		{remote: "10.0.0.1:80", match: false},
+		{remote: "10.0.0.1:80", xff: "1.2.3.4", match: true},
+		{remote: "10.0.0.1:80", xff: "1.2.3.4, 192.168.1.1", match: true},
+		{remote: "10.0.0.1:80", xff: "1.2.3.4, 5.6.7.8", match: false}, // spoofed by client
+		{remote: "10.0.0.1:80", xff: "10.0.0.2, 10.0.0.3", match: false},
+		{remote: "10.0.0.1:80", xrip: "1.2.3.4", match: true},
+		{remote: "10.0.0.1:80", xff: "1.2.3.4, invalid", xrip: "1.2.3.4", match: false},
+		{remote: "10.0.0.1:80", xff: "1.2.3.4, invalid, 10.0.0.2", match: false},
+		{remote: "[::1]:80", xff: "1.2.3.4", match: true},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = test.remote
+		if test.xff != "" {
+			req.Header.Set("X-Forwarded-For", test.xff)
+		}
+		if test.xrip != "" {
+			req.Header.Set("X-Real-Ip", test.xrip)
+		}
+
+		if match := m.Match(req); match != test.match {
+			t.Errorf("remote=%s, xff=%s, xrip=%s: expect match %v, but got %v",
+				test.remote, test.xff, test.xrip, test.match, match)
+		}
+	}
+
+	m, err = ClientIPForwarded("1.2.3.4", "5.6.7.8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "5.6.7.8:80"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	if !m.Match(req) {
+		t.Errorf("expect matched, but got not")
+	}
+
+	req.RemoteAddr = "10.0.0.1:80"
+	if m.Match(req) {
+		t.Errorf("expect not matched, but got matched")
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	for _, spec := range []string{"", "09:00", "Mon-Fri", "Xyz 09:00-17:00", "09:00-09:00",
+		"Mon 09:00-25:00", "09:00-17:00 Invalid/Zone", "09:00-17:00 UTC extra"} {
+		if _, err := Schedule(spec); err == nil {
+			t.Errorf("%s: expect an error, but got nil", spec)
+		}
+	}
+
+	defer func() { timeNow = time.Now }()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	tests := []struct {
+		spec  string
+		now   string
+		match bool
+	}{
+		{spec: "09:00-17:00 UTC", now: "2024-01-06T09:00:00Z", match: true},
+		{spec: "09:00-17:00 UTC", now: "2024-01-06T17:00:00Z", match: false},
+		{spec: "Mon-Fri 09:00-17:00 UTC", now: "2024-01-05T12:00:00Z", match: true},  // Fri
+		{spec: "Mon-Fri 09:00-17:00 UTC", now: "2024-01-06T12:00:00Z", match: false}, // Sat
+		{spec: "Sat,Sun 10:00-12:00 UTC", now: "2024-01-07T11:59:00Z", match: true},  // Sun
+		{spec: "Fri-Mon 10:00-12:00 UTC", now: "2024-01-08T11:00:00Z", match: true},  // Mon
+		{spec: "Fri-Mon 10:00-12:00 UTC", now: "2024-01-09T11:00:00Z", match: false}, // Tue
+		{spec: "Fri 22:00-02:00 UTC", now: "2024-01-05T23:00:00Z", match: true},      // Fri
+		{spec: "Fri 22:00-02:00 UTC", now: "2024-01-06T01:00:00Z", match: true},      // Sat
+		{spec: "Fri 22:00-02:00 UTC", now: "2024-01-06T23:00:00Z", match: false},     // Sat
+		{spec: "Mon 09:00-17:00 UTC", now: "2024-01-08T07:00:00-05:00", match: true},
+	}
+
+	for _, test := range tests {
+		m, err := Schedule(test.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		now, err := time.Parse(time.RFC3339, test.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		timeNow = func() time.Time { return now }
+		if match := m.Match(req); match != test.match {
+			t.Errorf("%s at %s: expect match %v, but got %v", test.spec, test.now, test.match, match)
+		}
+	}
+}
+
+func TestRouteBuilderSchedule(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC) } // Sat
+
+	router := NewRouter()
+	router.Path("/path").Schedule("Mon-Fri 09:00-17:00 UTC").GET(handler.Handler200)
+	router.Path("/path").GET(handler.Handler204)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/path", nil))
+	if rec.Code != 204 {
+		t.Errorf("expect status code %d, but got %d", 204, rec.Code)
+	}
+
+	// The route with the schedule is tried first in the time window.
+	timeNow = func() time.Time { return time.Date(2024, 1, 8, 12, 0, 0, 0, time.UTC) } // Mon
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/path", nil))
+	if rec.Code != 200 {
+		t.Errorf("expect status code %d, but got %d", 200, rec.Code)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expect a panic, but got nil")
+		}
+	}()
+	router.Path("/path").Schedule("invalid")
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/xgfone/go-apiserver/http/middleware"
 	"github.com/xgfone/go-apiserver/http/reqresp"
+	matcher "github.com/xgfone/go-http-matcher"
 )
 
 // Matcher is used to check whether the route matches the request.
@@ -28,6 +29,10 @@ type Matcher interface {
 
 // Route is a http request route.
 type Route struct {
+	// Name is the name of the route, which is used to build the url
+	// by the method Router.URL.
+	Name string `json:"name,omitempty" yaml:"name,omitempty" xml:"name,omitempty"`
+
 	// Priority is the priority of the route.
 	//
 	// The bigger the value, the higher the priority.
@@ -46,6 +51,11 @@ type Route struct {
 	Desc string `json:"desc,omitempty" yaml:"desc,omitempty" xml:"desc,omitempty"`
 
 	handler http.Handler
+	path    string          // the path template, such as "/path/{id}"
+	prefix  bool            // whether the path template is a path prefix
+	slash   bool            // whether the registered path has the trailing "/"
+	method  string          // the method of the matcher built by RouteBuilder
+	others  matcher.Matcher // the matcher without the method matcher
 }
 
 // NewRoute returns a new Route.
@@ -53,6 +63,12 @@ func NewRoute(priority int, matcher Matcher, handler http.Handler) Route {
 	return Route{Priority: priority, Matcher: matcher, Handler: handler}
 }
 
+// WithName returns a new Route with the name.
+func (r Route) WithName(name string) Route {
+	r.Name = name
+	return r
+}
+
 // WithDesc returns a new Route with the desc.
 func (r Route) WithDesc(desc string) Route {
 	r.Desc = desc
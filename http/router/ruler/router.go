@@ -16,10 +16,15 @@
 package ruler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"slices"
+	"strings"
 
 	"github.com/xgfone/go-apiserver/http/handler"
+	"github.com/xgfone/go-apiserver/http/header"
 	"github.com/xgfone/go-apiserver/http/middleware"
 )
 
@@ -34,6 +39,42 @@ type Router struct {
 	// Default: handler.Handler404
 	NotFound http.Handler
 
+	// MethodNotAllowed is used when the manager is used as http.Handler
+	// and does not find the route, but the routes, which only mismatch
+	// the method, are found. Before calling it, the response header "Allow"
+	// will be set to the methods of these routes, such as handler.Handler405.
+	//
+	// If nil, disable it and use NotFound instead.
+	//
+	// Default: nil
+	MethodNotAllowed http.Handler
+
+	// AutoOptions is used to respond the OPTIONS request with 204
+	// and the response header "Allow" listing the methods of the routes
+	// matching the request path, when no explicit OPTIONS route matches.
+	//
+	// Default: false
+	AutoOptions bool
+
+	// RedirectTrailingSlash is used to redirect the request path
+	// to the form registered by Path when the trailing "/" is different,
+	// such as "/foo/" to "/foo" if the route is registered as "/foo",
+	// or "/foo" to "/foo/" if the route is registered as "/foo/".
+	// If a route registered in the same form as the request path
+	// also matches the request, it handles the request without redirection.
+	// The redirect status code is 301 for GET and HEAD, or 308 for others
+	// to preserve the method and body.
+	//
+	// If false, the path matcher ignores the trailing "/" and the route
+	// handles both "/foo" and "/foo/" directly.
+	//
+	// Notice: the route registered by PathPrefix is never redirected,
+	// because its handler may distinguish the trailing "/" by itself,
+	// such as a file server for a directory.
+	//
+	// Default: false
+	RedirectTrailingSlash bool
+
 	// Middlewares is used to manage the middlewares and applied to each route
 	// when registering it. So, the middlewares will be run after routing
 	// and never be run if not found the route.
@@ -52,21 +93,194 @@ func (r *Router) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	for i, _len := 0, len(r.routes); i < _len; i++ {
 		route := &r.routes[i]
 		if route.Matcher.Match(req) {
+			if r.RedirectTrailingSlash && route.path != "" && !route.prefix &&
+				route.slash != hasTrailingSlash(req.URL.Path) {
+				if exact := r.matchTrailingSlash(req, i+1); exact != nil {
+					route = exact
+				} else {
+					redirectTrailingSlash(rw, req, route.slash)
+					return
+				}
+			}
+
 			route.ServeHTTP(rw, req)
 			return
 		}
 	}
 
-	if r.NotFound != nil {
+	if r.AutoOptions && req.Method == http.MethodOptions {
+		if methods := r.allowedMethods(req); len(methods) > 0 {
+			methods = append(methods, http.MethodOptions)
+			rw.Header().Set(header.HeaderAllow, strings.Join(methods, ", "))
+			rw.WriteHeader(204)
+			return
+		}
+	}
+
+	if r.MethodNotAllowed != nil {
+		if methods := r.allowedMethods(req); len(methods) > 0 {
+			rw.Header().Set(header.HeaderAllow, strings.Join(methods, ", "))
+			r.MethodNotAllowed.ServeHTTP(rw, req)
+			return
+		}
+	}
+
+	r.notFound(rw, req)
+}
+
+func (r *Router) notFound(rw http.ResponseWriter, req *http.Request) {
+	if r != nil && r.NotFound != nil {
 		r.NotFound.ServeHTTP(rw, req)
 	} else {
 		handler.Handler404.ServeHTTP(rw, req)
 	}
 }
 
+// matchTrailingSlash returns the first route from the index start,
+// which is registered by Path in the same trailing "/" form
+// as the request path and matches the request.
+func (r *Router) matchTrailingSlash(req *http.Request, start int) *Route {
+	slash := hasTrailingSlash(req.URL.Path)
+	for i, _len := start, len(r.routes); i < _len; i++ {
+		route := &r.routes[i]
+		if route.path != "" && !route.prefix && route.slash == slash && route.Matcher.Match(req) {
+			return route
+		}
+	}
+	return nil
+}
+
+func hasTrailingSlash(path string) bool {
+	return len(path) > 1 && path[len(path)-1] == '/'
+}
+
+func redirectTrailingSlash(rw http.ResponseWriter, req *http.Request, slash bool) {
+	u := *req.URL
+	u.RawPath = ""
+
+	// Collapse the leading "/" to avoid the open redirect,
+	// such as "//evil.com" which is a protocol-relative url.
+	if u.Path = strings.Trim(u.Path, "/"); u.Path == "" {
+		u.Path = "/"
+	} else if u.Path = "/" + u.Path; slash {
+		u.Path += "/"
+	}
+
+	code := http.StatusPermanentRedirect
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+
+	http.Redirect(rw, req, u.RequestURI(), code)
+}
+
+// allowedMethods returns the methods of the routes built by RouteBuilder,
+// which match the request except the method.
+func (r *Router) allowedMethods(req *http.Request) (methods []string) {
+	for i, _len := 0, len(r.routes); i < _len; i++ {
+		route := &r.routes[i]
+		if route.others != nil && !slices.Contains(methods, route.method) && route.others.Match(req) {
+			methods = append(methods, route.method)
+		}
+	}
+	slices.Sort(methods)
+	return
+}
+
 // Routes returns all the registered routes, which must be read-only.
 func (r *Router) Routes() (routes []Route) { return r.routes }
 
+// RouteInfo is the introspection information of a route.
+type RouteInfo struct {
+	Name     string `json:"name,omitempty" yaml:"name,omitempty" xml:"name,omitempty"`
+	Path     string `json:"path,omitempty" yaml:"path,omitempty" xml:"path,omitempty"`
+	Method   string `json:"method,omitempty" yaml:"method,omitempty" xml:"method,omitempty"`
+	Matcher  string `json:"matcher" yaml:"matcher" xml:"matcher"`
+	Priority int    `json:"priority" yaml:"priority" xml:"priority"`
+}
+
+// RouteInfos returns the snapshot of the information of all the registered
+// routes by the order of the matching precedence.
+func (r *Router) RouteInfos() []RouteInfo {
+	infos := make([]RouteInfo, len(r.routes))
+	for i, route := range r.routes {
+		infos[i] = RouteInfo{
+			Name:     route.Name,
+			Path:     route.path,
+			Method:   route.method,
+			Matcher:  route.Desc,
+			Priority: route.Priority,
+		}
+
+		if s, ok := route.Matcher.(fmt.Stringer); ok {
+			infos[i].Matcher = s.String()
+		}
+	}
+	return infos
+}
+
+// RouteInfosHandler returns a http handler to respond the route information
+// by the json format, which may be registered as the route "/debug/routes".
+func (r *Router) RouteInfosHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = handler.JSON(w, 200, r.RouteInfos())
+	})
+}
+
+// URL builds the url path of the route named name with the path parameters,
+// such as "/path/{id}" with map[string]string{"id": "123"} to "/path/123".
+//
+// The route must be built by RouteBuilder with the path or path prefix matcher.
+func (r *Router) URL(name string, params map[string]string) (string, error) {
+	if name == "" {
+		return "", errors.New("the route name must not be empty")
+	}
+
+	for i, _len := 0, len(r.routes); i < _len; i++ {
+		if route := &r.routes[i]; route.Name == name {
+			if route.path == "" {
+				return "", fmt.Errorf("the route '%s' has no path", name)
+			}
+			return buildURLPath(route.path, params)
+		}
+	}
+
+	return "", fmt.Errorf("no route named '%s'", name)
+}
+
+func buildURLPath(path string, params map[string]string) (string, error) {
+	if strings.IndexByte(path, '{') == -1 {
+		return path, nil
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(path) + 16)
+	for len(path) > 0 {
+		leftIndex := strings.IndexByte(path, '{')
+		if leftIndex == -1 {
+			break
+		}
+
+		rightIndex := strings.IndexByte(path, '}')
+		if rightIndex == -1 {
+			break
+		}
+
+		name := path[leftIndex+1 : rightIndex]
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("missing the path parameter '%s'", name)
+		}
+
+		buf.WriteString(path[:leftIndex])
+		buf.WriteString(url.PathEscape(value))
+		path = path[rightIndex+1:]
+	}
+
+	buf.WriteString(path)
+	return buf.String(), nil
+}
+
 // Register registers the route.
 //
 // NOTICE: if both routes match a request, the handler of the higher priority
@@ -18,9 +18,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/xgfone/go-apiserver/http/handler"
+	"github.com/xgfone/go-apiserver/http/middleware"
+	"github.com/xgfone/go-apiserver/http/reqresp"
 )
 
 func TestRouter(t *testing.T) {
@@ -48,3 +53,220 @@ func TestRouter(t *testing.T) {
 		t.Errorf("expect status code %d, but got %d", 204, rec.Code)
 	}
 }
+
+func TestRouterURL(t *testing.T) {
+	r := NewRouter()
+	r.Group("/v1").Path("/users/{uid}/books/{bid}").Name("book").GET(handler.Handler204)
+	r.Path("/users").Name("users").GET(handler.Handler204)
+	r.Host("localhost").Name("nopath").GET(handler.Handler204)
+
+	if path, err := r.URL("book", map[string]string{"uid": "1", "bid": "a b"}); err != nil {
+		t.Error(err)
+	} else if path != "/v1/users/1/books/a%20b" {
+		t.Errorf("expect path '%s', but got '%s'", "/v1/users/1/books/a%20b", path)
+	}
+
+	if path, err := r.URL("users", nil); err != nil {
+		t.Error(err)
+	} else if path != "/users" {
+		t.Errorf("expect path '%s', but got '%s'", "/users", path)
+	}
+
+	if _, err := r.URL("book", map[string]string{"uid": "1"}); err == nil {
+		t.Errorf("expect an error for the missing parameter, but got nil")
+	}
+	if _, err := r.URL("nopath", nil); err == nil {
+		t.Errorf("expect an error for the route without path, but got nil")
+	}
+	if _, err := r.URL("unknown", nil); err == nil {
+		t.Errorf("expect an error for the unknown route, but got nil")
+	}
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	r := NewRouter()
+	r.Path("/path").PUT(handler.Handler204)
+	r.Path("/path").GET(handler.Handler204)
+	r.Path("/other").POST(handler.Handler204)
+
+	req := httptest.NewRequest(http.MethodDelete, "/path", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != 404 {
+		t.Errorf("expect status code %d, but got %d", 404, rec.Code)
+	}
+
+	r.MethodNotAllowed = handler.Handler405
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != 405 {
+		t.Errorf("expect status code %d, but got %d", 405, rec.Code)
+	} else if allow := rec.Header().Get("Allow"); allow != "GET, PUT" {
+		t.Errorf("expect Allow '%s', but got '%s'", "GET, PUT", allow)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/none", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != 404 {
+		t.Errorf("expect status code %d, but got %d", 404, rec.Code)
+	}
+}
+
+func TestRouterAutoOptions(t *testing.T) {
+	r := NewRouter()
+	r.Path("/path").PUT(handler.Handler200)
+	r.Path("/path").GET(handler.Handler200)
+	r.Path("/other").OPTIONS(handler.Handler200)
+	r.Path("/other").GET(handler.Handler200)
+
+	req := httptest.NewRequest(http.MethodOptions, "/path", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != 404 {
+		t.Errorf("expect status code %d, but got %d", 404, rec.Code)
+	}
+
+	r.AutoOptions = true
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != 204 {
+		t.Errorf("expect status code %d, but got %d", 204, rec.Code)
+	} else if allow := rec.Header().Get("Allow"); allow != "GET, PUT, OPTIONS" {
+		t.Errorf("expect Allow '%s', but got '%s'", "GET, PUT, OPTIONS", allow)
+	}
+
+	req = httptest.NewRequest(http.MethodOptions, "/other", nil)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Errorf("expect status code %d, but got %d", 200, rec.Code)
+	}
+}
+
+func TestRouterRedirectTrailingSlash(t *testing.T) {
+	r := NewRouter()
+	r.RedirectTrailingSlash = true
+	r.Path("/path").GET(handler.Handler200)
+	r.Path("/path").POST(handler.Handler200)
+	r.PathPrefix("/prefix").GET(handler.Handler200)
+	r.Path("/slash/").GET(handler.Handler200)
+	r.Path("/both").GET(handler.Handler200)
+	r.Path("/both/").GET(handler.Handler204)
+	r.Path("/{a}/{b}/").GET(handler.Handler200)
+
+	tests := []struct {
+		method   string
+		path     string
+		code     int
+		location string
+	}{
+		{method: http.MethodGet, path: "/path", code: 200},
+		{method: http.MethodGet, path: "/path/?a=1", code: 301, location: "/path?a=1"},
+		{method: http.MethodPost, path: "/path/", code: 308, location: "/path"},
+		{method: http.MethodGet, path: "/prefix/", code: 200},
+		{method: http.MethodGet, path: "/slash/", code: 200},
+		{method: http.MethodGet, path: "/slash?a=1", code: 301, location: "/slash/?a=1"},
+		{method: http.MethodGet, path: "/both", code: 200},
+		{method: http.MethodGet, path: "/both/", code: 204},
+		{method: http.MethodGet, path: "//evil.com", code: 301, location: "/evil.com/"},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(test.method, test.path, nil))
+		if rec.Code != test.code {
+			t.Errorf("%s %s: expect status code %d, but got %d", test.method, test.path, test.code, rec.Code)
+		} else if location := rec.Header().Get("Location"); location != test.location {
+			t.Errorf("%s %s: expect location '%s', but got '%s'", test.method, test.path, test.location, location)
+		}
+	}
+}
+
+func TestRouteBuilderTimeout(t *testing.T) {
+	r := NewRouter()
+	r.Path("/path").Timeout(time.Millisecond * 10).GETFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	rec := httptest.NewRecorder()
+	rw := reqresp.AcquireResponseWriter(rec)
+	r.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/path", nil))
+	reqresp.ReleaseResponseWriter(rw)
+	if rec.Code != 504 {
+		t.Errorf("expect status code %d, but got %d", 504, rec.Code)
+	}
+}
+
+func TestRouterRouteInfos(t *testing.T) {
+	r := NewRouter()
+	r.Path("/path").Name("path").GET(handler.Handler204)
+	r.PathPrefix("/prefix").Host("localhost").POST(handler.Handler204)
+
+	infos := r.RouteInfos()
+	if len(infos) != 2 {
+		t.Fatalf("expect %d routes, but got %d", 2, len(infos))
+	}
+
+	expect := RouteInfo{
+		Path:     "/prefix",
+		Method:   "POST",
+		Matcher:  "(Host(`localhost`) && PathPrefix(`/prefix`) && Method(`POST`))",
+		Priority: infos[0].Priority,
+	}
+	if infos[0] != expect {
+		t.Errorf("expect route info %+v, but got %+v", expect, infos[0])
+	}
+	if infos[1].Name != "path" || infos[1].Path != "/path" || infos[1].Method != "GET" {
+		t.Errorf("unexpected route info %+v", infos[1])
+	}
+
+	infos[0].Name = "modified"
+	if r.Routes()[0].Name != "" {
+		t.Errorf("expect the snapshot, but the route is modified")
+	}
+
+	rec := httptest.NewRecorder()
+	r.RouteInfosHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+	if rec.Code != 200 {
+		t.Errorf("expect status code %d, but got %d", 200, rec.Code)
+	} else if !strings.Contains(rec.Body.String(), `"name":"path"`) {
+		t.Errorf("unexpected response body: %s", rec.Body.String())
+	}
+}
+
+func TestRouteBuilderUsePriority(t *testing.T) {
+	var orders []string
+	newMiddleware := func(name string) middleware.MiddlewareFunc {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				orders = append(orders, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	r := NewRouter()
+	r.Path("/path").
+		UseFunc(newMiddleware("logger")).
+		UsePriority(10, newMiddleware("metric")).
+		UsePriority(-1, newMiddleware("auth")).
+		UseFunc(newMiddleware("trace")).
+		GET(handler.Handler204)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/path", nil))
+
+	expects := []string{"auth", "logger", "trace", "metric"}
+	if !slices.Equal(orders, expects) {
+		t.Errorf("expect orders %v, but got %v", expects, orders)
+	}
+
+	var names []string
+	b := NewRouteBuilder(func(Route) {}).UsePriority(1, middleware.New("name", 0, newMiddleware("name")))
+	for _, m := range b.mdws {
+		names = append(names, middleware.GetName(m))
+	}
+	if !slices.Equal(names, []string{"name"}) {
+		t.Errorf("expect names %v, but got %v", []string{"name"}, names)
+	}
+}
@@ -27,15 +27,18 @@ import (
 var _ error = Error{}
 
 // Error is used to stand for an error based the integer code.
+//
+// Notice: for the XML encoding, Data only supports the struct, slice, etc.,
+// which can be encoded by encoding/xml, but not the map.
 type Error struct {
-	Data    any    `json:",omitempty"`
-	Code    int    `json:",omitempty"`
-	Message string `json:",omitempty"`
+	Data    any    `json:",omitempty" xml:",omitempty"`
+	Code    int    `json:",omitempty" xml:",omitempty"`
+	Message string `json:",omitempty" xml:",omitempty"`
 
-	Err error `json:"-"`
-	Ctx any   `json:"-"`
+	Err error `json:"-" xml:"-"`
+	Ctx any   `json:"-" xml:"-"`
 
-	Status int `json:"-"`
+	Status int `json:"-" xml:"-"`
 }
 
 // NewError returns a new Error with the code.
@@ -92,6 +95,19 @@ func (e Error) WithError(err error) Error {
 	return e
 }
 
+// Wrap returns a new Error wrapping the error as the cause,
+// which can be got by errors.Is/As through Unwrap.
+//
+// Unlike WithError, it keeps the message unchanged
+// unless the message is empty.
+func (e Error) Wrap(err error) Error {
+	if e.Message == "" && err != nil {
+		e.Message = err.Error()
+	}
+	e.Err = err
+	return e
+}
+
 // WithMessage returns a new Error with the message.
 func (e Error) WithMessage(msg string) Error {
 	e.Message = msg
@@ -0,0 +1,98 @@
+// Copyright 2024 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codeint
+
+import "net/http"
+
+// The gRPC status codes, which are the same as google.golang.org/grpc/codes,
+// so that the package does not depend on grpc.
+const (
+	grpcOK                 uint32 = 0
+	grpcUnknown            uint32 = 2
+	grpcInvalidArgument    uint32 = 3
+	grpcDeadlineExceeded   uint32 = 4
+	grpcNotFound           uint32 = 5
+	grpcAlreadyExists      uint32 = 6
+	grpcPermissionDenied   uint32 = 7
+	grpcResourceExhausted  uint32 = 8
+	grpcFailedPrecondition uint32 = 9
+	grpcUnimplemented      uint32 = 12
+	grpcInternal           uint32 = 13
+	grpcUnavailable        uint32 = 14
+	grpcUnauthenticated    uint32 = 16
+)
+
+// GRPCCode returns the gRPC status code of the error code by its http
+// status code, see StatusOf, which may be converted to codes.Code
+// of google.golang.org/grpc/codes directly, such as
+//
+//	codes.Code(codeint.GRPCCode(code))
+//
+// The mapping is as follow:
+//
+//	2xx: OK
+//	400: InvalidArgument
+//	401: Unauthenticated
+//	403: PermissionDenied
+//	404: NotFound
+//	409: AlreadyExists
+//	412: FailedPrecondition
+//	429: ResourceExhausted
+//	500: Internal
+//	501: Unimplemented
+//	502: Unavailable
+//	503: Unavailable
+//	504: DeadlineExceeded
+//	Others: Unknown
+func GRPCCode(code int) uint32 {
+	switch status := StatusOf(code); status {
+	case http.StatusBadRequest:
+		return grpcInvalidArgument
+	case http.StatusUnauthorized:
+		return grpcUnauthenticated
+	case http.StatusForbidden:
+		return grpcPermissionDenied
+	case http.StatusNotFound:
+		return grpcNotFound
+	case http.StatusConflict:
+		return grpcAlreadyExists
+	case http.StatusPreconditionFailed:
+		return grpcFailedPrecondition
+	case http.StatusTooManyRequests:
+		return grpcResourceExhausted
+	case http.StatusInternalServerError:
+		return grpcInternal
+	case http.StatusNotImplemented:
+		return grpcUnimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return grpcUnavailable
+	case http.StatusGatewayTimeout:
+		return grpcDeadlineExceeded
+	default:
+		if 200 <= status && status < 300 {
+			return grpcOK
+		}
+		return grpcUnknown
+	}
+}
+
+// GRPCCode returns the gRPC status code of the error by its http status code,
+// see StatusCode and the function GRPCCode.
+func (e Error) GRPCCode() uint32 {
+	if e.Status != 0 {
+		return GRPCCode(e.Status)
+	}
+	return GRPCCode(e.Code)
+}
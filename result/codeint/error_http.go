@@ -15,7 +15,9 @@
 package codeint
 
 import (
+	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/xgfone/go-apiserver/http/handler"
 )
@@ -37,19 +39,69 @@ var (
 	ErrGatewayTimeout       = NewError(http.StatusGatewayTimeout)       // 504
 )
 
+var (
+	slock    sync.RWMutex
+	statuses = map[int]int{
+		http.StatusBadRequest:           http.StatusBadRequest,
+		http.StatusUnauthorized:         http.StatusUnauthorized,
+		http.StatusForbidden:            http.StatusForbidden,
+		http.StatusNotFound:             http.StatusNotFound,
+		http.StatusConflict:             http.StatusConflict,
+		http.StatusUnsupportedMediaType: http.StatusUnsupportedMediaType,
+		http.StatusTooManyRequests:      http.StatusTooManyRequests,
+		http.StatusInternalServerError:  http.StatusInternalServerError,
+		http.StatusBadGateway:           http.StatusBadGateway,
+		http.StatusServiceUnavailable:   http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:       http.StatusGatewayTimeout,
+	}
+)
+
+// RegisterStatus registers the http status code for the error code,
+// so that the error with the business code, such as 10001,
+// can be responded with a proper status code, such as 400.
+//
+// The codes of the pre-defined errors have been registered by default.
+//
+// NOTICE: it only takes effect on the error whose Status is ZERO,
+// such as Error{Code: 10001}, because NewError has set Status.
+func RegisterStatus(code, status int) {
+	if status < 100 || status >= 600 {
+		panic(fmt.Errorf("codeint.RegisterStatus: invalid http status code %d", status))
+	}
+
+	slock.Lock()
+	statuses[code] = status
+	slock.Unlock()
+}
+
+// StatusOf returns the http status code of the error code.
+//
+// If code has been registered by RegisterStatus, return the registered.
+// Or, return code if it is in [100, 599].
+// Or, return 500.
+func StatusOf(code int) int {
+	slock.RLock()
+	status, ok := statuses[code]
+	slock.RUnlock()
+
+	if ok {
+		return status
+	}
+	if 100 <= code && code < 600 {
+		return code
+	}
+	return 500
+}
+
 // StatusCode returns the http status code.
 //
 // If Status is not equal to 0, return it.
-// Or, return Code if it is in [100, 599].
-// Or, return 500.
+// Or, return StatusOf(Code).
 func (e Error) StatusCode() int {
 	if e.Status != 0 {
 		return e.Status
 	}
-	if 100 <= e.Code && e.Code < 600 {
-		return e.Code
-	}
-	return 500
+	return StatusOf(e.Code)
 }
 
 // ServeHTTP implements the interface http.Handler.
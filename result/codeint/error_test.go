@@ -50,3 +50,85 @@ func TestError(t *testing.T) {
 		t.Errorf("expect '%s', but got '%s'", expect, body)
 	}
 }
+
+func TestRegisterStatus(t *testing.T) {
+	RegisterStatus(10001, 400)
+	defer delete(statuses, 10001)
+
+	if code := StatusOf(10001); code != 400 {
+		t.Errorf("expect status code %d, but got %d", 400, code)
+	}
+	if code := StatusOf(10002); code != 500 {
+		t.Errorf("expect status code %d, but got %d", 500, code)
+	}
+	if code := StatusOf(404); code != 404 {
+		t.Errorf("expect status code %d, but got %d", 404, code)
+	}
+
+	if code := StatusOf(409); code != 409 {
+		t.Errorf("expect status code %d, but got %d", 409, code)
+	}
+
+	if code := (Error{Code: 10001}).StatusCode(); code != 400 {
+		t.Errorf("expect status code %d, but got %d", 400, code)
+	}
+	if code := NewError(10001).StatusCode(); code != 500 {
+		t.Errorf("expect status code %d, but got %d", 500, code)
+	}
+	if code := (Error{Code: 10001}).WithStatus(409).StatusCode(); code != 409 {
+		t.Errorf("expect status code %d, but got %d", 409, code)
+	}
+}
+
+func TestErrorWrap(t *testing.T) {
+	cause := errors.New("cause")
+
+	err := ErrBadRequest.WithMessage("invalid name").Wrap(cause)
+	if !errors.Is(err, cause) {
+		t.Errorf("expect the error wraps the cause, but got not")
+	}
+	if err.Message != "invalid name" {
+		t.Errorf("expect message '%s', but got '%s'", "invalid name", err.Message)
+	}
+
+	if err = ErrBadRequest.Wrap(cause); err.Message != "cause" {
+		t.Errorf("expect message '%s', but got '%s'", "cause", err.Message)
+	}
+
+	if ErrBadRequest.Err != nil {
+		t.Errorf("unexpect the original error is changed")
+	}
+}
+
+func TestGRPCCode(t *testing.T) {
+	RegisterStatus(10001, 429)
+	defer delete(statuses, 10001)
+
+	tests := []struct {
+		code int
+		grpc uint32
+	}{
+		{code: 200, grpc: 0},
+		{code: 400, grpc: 3},
+		{code: 401, grpc: 16},
+		{code: 403, grpc: 7},
+		{code: 404, grpc: 5},
+		{code: 429, grpc: 8},
+		{code: 500, grpc: 13},
+		{code: 503, grpc: 14},
+		{code: 504, grpc: 4},
+		{code: 418, grpc: 2},
+		{code: 10001, grpc: 8},
+		{code: 10002, grpc: 13},
+	}
+
+	for _, test := range tests {
+		if code := GRPCCode(test.code); code != test.grpc {
+			t.Errorf("%d: expect grpc code %d, but got %d", test.code, test.grpc, code)
+		}
+	}
+
+	if code := ErrNotFound.WithStatus(403).GRPCCode(); code != 7 {
+		t.Errorf("expect grpc code %d, but got %d", 7, code)
+	}
+}